@@ -0,0 +1,95 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// ErrTooBig is returned for content bigger than MaxSize
+var ErrTooBig = errors.New("blob is too big")
+
+// MaxSize is the biggest accepted blob, the bot API does not give bigger files
+const MaxSize = 20 * 1024 * 1024
+
+// Store keeps uploaded files under keys derived from their content,
+// so the same file uploaded twice is stored once
+type Store interface {
+	// Put saves the content and returns its key
+	Put(ctx context.Context, r io.Reader) (string, error)
+	Get(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Object is a stored blob opened for reading
+type Object interface {
+	io.ReadSeekCloser
+	Info() Info
+}
+
+type Info struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// keys are sha256 of the content with an extension of its type
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}\.[a-z0-9]+$`)
+
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// ReadAll reads at most MaxSize bytes, bigger content is ErrTooBig rather
+// than silently cut
+func ReadAll(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSize {
+		return nil, ErrTooBig
+	}
+	return data, nil
+}
+
+// readAll reads the content and derives its key
+func readAll(r io.Reader) (data []byte, key string, err error) {
+	data, err = ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return data, hex.EncodeToString(sum[:]) + extension(http.DetectContentType(data)), nil
+}
+
+func extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "application/octet-stream":
+		return ".bin"
+	}
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+func contentType(key string) string {
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,138 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// a 1x1 png
+var png = []byte{
+	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0x15, 0xc4,
+	0x89, 0x00, 0x00, 0x00, 0x0a, 0x49, 0x44, 0x41, 0x54, 0x78, 0x9c, 0x63, 0x00, 0x01, 0x00, 0x00,
+	0x05, 0x00, 0x01, 0x0d, 0x0a, 0x2d, 0xb4, 0x00, 0x00, 0x00, 0x00, 0x49, 0x45, 0x4e, 0x44, 0xae,
+	0x42, 0x60, 0x82,
+}
+
+func TestLocal(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+func TestLocalFailure(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := store.Put(context.Background(), bytes.NewReader(png))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the directory of the key is replaced by a file, the storage is broken
+	// rather than the blob missing
+	dir := filepath.Join(root, key[:2])
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), key); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a storage error, got %v", err)
+	}
+}
+
+// TestS3 runs against MinIO started with docker/minio.yaml:
+// S3_ENDPOINT=localhost:9000 S3_ACCESS_KEY=... S3_SECRET_KEY=... go test ./blob
+func TestS3(t *testing.T) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT is not set")
+	}
+	store, err := NewS3(context.Background(), S3Config{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		Bucket:    "blob-test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	key, err := store.Put(ctx, bytes.NewReader(png))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidKey(key) || key[len(key)-4:] != ".png" {
+		t.Fatalf("unexpected key %s", key)
+	}
+
+	again, err := store.Put(ctx, bytes.NewReader(png))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != key {
+		t.Fatalf("same content stored under %s and %s", key, again)
+	}
+
+	object, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(object)
+	object.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, png) {
+		t.Fatal("stored content differs")
+	}
+	if info := object.Info(); info.ContentType != "image/png" || info.Size != int64(len(png)) {
+		t.Fatalf("unexpected info %+v", info)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.Delete(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for deleted key, got %v", err)
+	}
+	if _, err := store.Get(ctx, "../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for invalid key, got %v", err)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	data, err := ReadAll(bytes.NewReader(make([]byte, MaxSize)))
+	if err != nil || len(data) != MaxSize {
+		t.Errorf("ReadAll(MaxSize) = %d bytes, %v", len(data), err)
+	}
+	if _, err := ReadAll(bytes.NewReader(make([]byte, MaxSize+1))); !errors.Is(err, ErrTooBig) {
+		t.Errorf("ReadAll(MaxSize+1) error = %v, want ErrTooBig", err)
+	}
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put(context.Background(), bytes.NewReader(make([]byte, MaxSize+1))); !errors.Is(err, ErrTooBig) {
+		t.Errorf("Put(MaxSize+1) error = %v, want ErrTooBig", err)
+	}
+}
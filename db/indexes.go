@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func CreateIndexes(ctx context.Context, mongoClient *mongo.Client) error {
+	database := mongoClient.Database(Database)
+
+	_, err := database.Collection("search_requests").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(model.SearchRequestTTL.Seconds())),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = database.Collection("inventories").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "items.name", Value: 1}}},
+		{Keys: bson.D{{Key: "items.medicine_id", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = database.Collection("aptekas").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the default language stems russian words of the address
+	_, err = database.Collection("houses").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "address", Value: "text"}},
+			Options: options.Index().SetDefaultLanguage("russian"),
+		},
+		{Keys: bson.D{
+			{Key: "active", Value: 1},
+			{Key: "city", Value: 1},
+			{Key: "published_at", Value: -1},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// a user has at most one alert per slot
+	_, err = database.Collection("house_alerts").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "chat_id", Value: 1}, {Key: "slot", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "city", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = database.Collection("moderation_log").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "house_id", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	return err
+}
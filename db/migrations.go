@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"log"
+
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrate brings the documents written by older versions up to date, it is
+// safe to run on every start
+func Migrate(ctx context.Context, mongoClient *mongo.Client) error {
+	database := mongoClient.Database(Database)
+
+	// listings published before moderation was introduced have no status,
+	// only the ones with an owner are approved
+	res, err := database.Collection("houses").UpdateMany(ctx,
+		bson.M{"status": bson.M{"$exists": false}, "owner_id": bson.M{"$nin": bson.A{nil, 0}}},
+		bson.M{"$set": bson.M{"status": model.HouseApproved}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount > 0 {
+		log.Printf("Approved %d houses published before moderation", res.ModifiedCount)
+	}
+
+	// nobody can edit or moderate a listing without an owner, it is hidden
+	res, err = database.Collection("houses").UpdateMany(ctx,
+		bson.M{"status": bson.M{"$exists": false}, "owner_id": bson.M{"$in": bson.A{nil, 0}}, "active": true},
+		bson.M{"$set": bson.M{"active": false}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount > 0 {
+		log.Printf("Hid %d houses without an owner", res.ModifiedCount)
+	}
+	return nil
+}
@@ -5,9 +5,15 @@ go 1.24
 require github.com/PaulSonOfLars/gotgbot/v2 v2.0.0-rc.29
 
 require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/go-ini/ini v1.67.0 // indirect
+	github.com/goccy/go-json v0.10.3 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
-	github.com/klauspost/compress v1.17.4 // indirect
+	github.com/klauspost/compress v1.17.11 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
+	github.com/minio/md5-simd v1.1.2 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
+	github.com/rs/xid v1.6.0 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.1.2 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
@@ -15,7 +21,7 @@ require (
 	golang.org/x/crypto v0.28.0 // indirect
 	golang.org/x/net v0.30.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
-	golang.org/x/text v0.19.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
 )
 
 require (
@@ -23,6 +29,9 @@ require (
 	github.com/gorilla/mux v1.8.1
 	github.com/jellydator/ttlcache/v3 v3.3.0
 	github.com/jub0bs/fcors v0.9.0
+	github.com/minio/minio-go/v7 v7.0.80
 	github.com/sashabaranov/go-openai v1.35.7
 	go.mongodb.org/mongo-driver v1.17.1
+	golang.org/x/image v0.21.0
+	golang.org/x/text v0.19.0
 )
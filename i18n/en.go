@@ -0,0 +1,245 @@
+package i18n
+
+var en = Catalog{
+	"default":              "Which medicines are you looking for? Type their names or just record a voice message 😊",
+	"voice_too_long":       "Your voice message is too long",
+	"voice_not_recognized": "Could not recognize the voice message, try again or type the text",
+	"you_said":             "You said: “%s”",
+	"searching":            "Looking for pharmacies",
+
+	"create_apteka":      "Press the button below to create a pharmacy",
+	"nothing_found":      "Sorry, no pharmacies have the medicines you need 😔",
+	"no_medicines":       "Could not make out the medicine names, please try again",
+	"found_aptekas":      "Found %d pharmacy that has the medicines you need|Found %d pharmacies that have the medicines you need",
+	"did_you_mean":       "Not sure I got “%s” right. Did you mean:",
+	"suggestion_expired": "This search is outdated, please search again",
+	"not_in_catalog":     "Not found in the catalog: %s",
+	"distance_m":         "%d m",
+	"distance_km":        "%s km",
+
+	"not_pharmacist":       "Only pharmacists can upload stock",
+	"stock_file_too_big":   "The file is too big, the maximum size is 20 MB",
+	"stock_processing":     "Processing the stock file",
+	"stock_unsupported":    "Supported are 1C exports in XML (CommerceML), CSV and XLSX",
+	"stock_broken":         "Could not read the file, check the export format",
+	"stock_updated":        "Stock updated ✅",
+	"stock_imported":       "Items imported: %d",
+	"stock_unmatched":      "Not found in the catalog: %d",
+	"stock_errors":         "Errors: %d",
+	"stock_line":           "line %d",
+	"stock_error_quantity": "wrong quantity %q",
+	"stock_choose_apteka":  "Which pharmacy is the stock for?",
+	"stock_expired":        "The file is outdated, send it again",
+
+	"apteka_invite":       "Invite the pharmacists of the branch with /invite",
+	"apteka_created":      "The pharmacy is created! ✅",
+	"apteka_upload_stock": "Now send me the stock file exported from 1C",
+	"apteka_pending":      "The request is sent to the administrator. After the review the pharmacy appears in the search and you become its pharmacist ⏳",
+	"apteka_review":       "🆕 New pharmacy for review",
+	"apteka_approved":     "The pharmacy is approved! ✅",
+	"apteka_rejected":     "The pharmacy did not pass the review ❌",
+	"apteka_refused":      "❌ Rejected",
+	"apteka_reviewed":     "This pharmacy is already reviewed",
+	"no_access":           "You have no access to this pharmacy",
+	"no_aptekas":          "You have no pharmacies yet, create one with /create_apteka",
+	"invite_who":          "Whom do you want to invite?",
+	"invite_created":      "Send this link to your colleague, it works once within 7 days:",
+	"invite_invalid":      "The invite is invalid or already used",
+	"joined_apteka":       "You are added to the pharmacy as a pharmacist ✅",
+	"joined_chain":        "You are now a manager of the pharmacy chain ✅\n\nCreate branches with /create_apteka and invite pharmacists with /invite",
+	"create_chain_usage":  "Give the chain a name: /create_chain Name",
+
+	"ask_location":   "Send your location and I will show the nearest pharmacies",
+	"location_saved": "Location saved 📍 Pharmacies are now sorted by distance",
+
+	"open_24x7":       "Open 24/7",
+	"open_until":      "Open until %s",
+	"open":            "Open",
+	"closed":          "Closed",
+	"closed_opens_at": "Closed, opens at %s",
+	"closed_opens_on": "Closed, opens %s at %s",
+	"weekday_sun":     "Sun",
+	"weekday_mon":     "Mon",
+	"weekday_tue":     "Tue",
+	"weekday_wed":     "Wed",
+	"weekday_thu":     "Thu",
+	"weekday_fri":     "Fri",
+	"weekday_sat":     "Sat",
+	"inventory_never": "stock was never uploaded",
+	"inventory_today": "stock updated today",
+	"inventory_days":  "stock updated %d day ago|stock updated %d days ago",
+	"inventory_reminder": "⏰ Time to update the stock of “%s”, %s: %s.\n\n" +
+		"Until it is updated, the pharmacy is shown below the others in search. " +
+		"Send me the stock file exported from 1C",
+	"inventory_escalation": "⚠️ Branch “%s”, %s does not update its stock: %s, reminders ignored: %d",
+
+	"webapp_error":      "Something went wrong - please try again",
+	"validation_errors": "Check the form:",
+	"label_name":        "Name",
+	"label_city":        "City",
+	"label_address":     "Address",
+	"label_phone":       "Phone",
+	"label_room_count":  "Rooms",
+	"label_price":       "Price",
+	"label_work_hours":  "Opening hours",
+	"label_location":    "Location",
+	"label_rooms_min":   "Rooms from",
+	"label_rooms_max":   "Rooms up to",
+	"label_price_min":   "Price from",
+	"label_price_max":   "Price up to",
+	"label_sort":        "Sort",
+	"label_page":        "Page",
+	"label_limit":       "Listings per page",
+
+	"error_required":           "required",
+	"error_too_short":          "at least %d characters",
+	"error_too_long":           "at most %d characters",
+	"error_range":              "from %d to %d",
+	"error_format":             "wrong format",
+	"error_type":               "wrong value type",
+	"error_invalid":            "wrong value",
+	"error_phone_format":       "a number of Kyrgyzstan, Kazakhstan or Russia, e.g. 0555 123 456",
+	"error_work_hours_format":  "write as «Пн-Пт 9:00-21:00, Сб 10:00-18:00»",
+	"error_work_hours_invalid": "unknown timezone or wrong date",
+	"error_location_invalid":   "wrong coordinates",
+	"error_sort_format":        "one of new, price, -price, rooms",
+
+	"photo_unsupported": "Could not open the photo, send a JPEG, PNG or WebP image",
+	"photo_too_big":     "The photo is too big, at most %d MB",
+	"too_many_photos":   "A listing can have at most %d photos",
+	"photo_as_file":     "To attach a photo to a listing, send it as a photo rather than a file",
+
+	"house_preview":        "Check the listing before publishing:",
+	"house_edit":           "Fix the form and send it again, the photos are kept",
+	"house_cancelled":      "The listing is deleted",
+	"house_not_found":      "The listing is not found or already published",
+	"house_unavailable":    "The listing is no longer available",
+	"houses_not_found":     "No listings match your search 😔\n\nExample: /houses Bishkek 2-3",
+	"house_search_expired": "The search is outdated, run /houses again",
+	"houses_page":          "Found %d listing, page %d of %d|Found %d listings, page %d of %d",
+	"rooms":                "%d room|%d rooms",
+	"photos":               "%d photo|%d photos",
+	"price":                "%s som",
+	"price_negotiable":     "Price negotiable",
+	"bounds_from":          "from %d",
+	"bounds_to":            "up to %d",
+
+	"no_listings":             "You have no listings yet",
+	"status_pending":          "⏳ Under review",
+	"status_rejected":         "❌ Rejected: %s",
+	"status_published":        "✅ Published",
+	"status_unpublished":      "⏸ Unpublished",
+	"status_draft":            "📝 Draft",
+	"listing_edit":            "Press the button below, fix the listing and send the form",
+	"listing_updated":         "The listing is updated ✅",
+	"listing_send_photos":     "Send new photos, they will replace the current ones. Press “Done” when finished",
+	"listing_no_photos":       "You have not sent any photos yet",
+	"listing_photos_replaced": "Photos updated ✅",
+
+	"verify_phone":      "Confirm your phone number: listings with a confirmed number are reviewed faster. The number in the listing must match your Telegram number",
+	"phone_verified":    "Number confirmed ✅",
+	"contact_not_yours": "Send your own contact with the “Confirm number” button",
+	"phone_unsupported": "Only numbers of Kyrgyzstan, Kazakhstan and Russia are supported",
+	"phone_mismatch":    "The number %s is saved, but it does not match the number in your listings",
+
+	"house_submitted":            "The listing is sent for review ⏳ We will let you know when it is checked",
+	"house_approved":             "The listing passed the review and is published ✅",
+	"house_rejected":             "The listing is rejected by a moderator ❌\nReason: %s\n\nFix it in /my_listings and submit again",
+	"moderation_new":             "🆕 Listing for review",
+	"moderation_approved":        "✅ Approved",
+	"moderation_rejected":        "❌ Rejected: %s",
+	"moderation_done":            "This listing is already reviewed",
+	"moderation_ask_reason":      "Write the reason for rejection in reply to this message",
+	"moderation_reason_too_long": "The reason is too long, at most %d characters",
+	"reject_phone":               "Wrong phone number",
+	"reject_duplicate":           "Duplicate listing",
+	"reject_content":             "Prohibited content",
+	"reject_photos":              "Inappropriate photos",
+	"reject_address":             "Incomplete or wrong address",
+	"flag_banned_word":           "Prohibited words",
+	"flag_unverified_phone":      "The phone number is not confirmed by the owner",
+	"flag_duplicate_address":     "The address is used in other listings",
+	"flag_duplicate_phone":       "The phone is used in listings of another owner",
+
+	"alert_saved":     "Done 🔔 I will let you know about matching listings. Subscriptions: /alerts",
+	"alert_deleted":   "Subscription deleted",
+	"too_many_alerts": "You already have %d subscriptions, delete some with /alerts",
+	"no_alerts":       "You have no subscriptions. Search with /houses and press “Notify about new”",
+	"alerts":          "Your subscriptions, press one to unsubscribe:",
+	"all_houses":      "All listings",
+	"new_house":       "🔔 New listing for your subscription",
+
+	"language_choose":  "Choose the language:",
+	"language_changed": "Language changed ✅",
+
+	"session_not_allowed":  "You can not start a session. Perhaps you have no subscription.",
+	"session_topic":        "Topic %d",
+	"session_choose_topic": "Choose a topic:",
+	"session_rate":         "Rate the session from 1 to 5:",
+	"session_topic_first":  "Choose a topic first, then you can write messages.",
+	"session_topic_needed": "Choose a topic first.",
+	"session_last_closed":  "The last session is closed. You can start a new dialog.",
+	"session_closed":       "The session is closed. You can start a new dialog.",
+	"session_limit":        "The message limit is reached. You can start a new dialog.",
+	"session_none":         "You have no active session.",
+	"session_finished":     "The session is over. You can start a new dialog.",
+	"session_trial":        "Trial activated! You have 2 sessions.",
+	"session_expired":      "Your subscription has expired. Buy a new one",
+	"session_unlimited":    "You have unlimited sessions.",
+	"session_no_left":      "You have no sessions left. Buy more",
+	"session_allowed":      "You can start a session.",
+	"server_error":         "Server error, please try again later.",
+	"support_prompt":       "Send your message to support:",
+	"support_sent":         "Your message is sent to support.",
+	"feedback_prompt":      "Send your feedback about the bot:",
+	"feedback_thanks":      "Thank you, your feedback matters.",
+
+	"user_not_found":       "Error: user not found.",
+	"plan_premium_active":  "Your 'Premium' plan is already active. It expires on %s.\nYou can renew it after it ends.",
+	"plan_standard_active": "Your 'Standard' plan is active. You can upgrade to 'Premium'.",
+	"plan_basic_active":    "Your 'Basic' plan is active. You can upgrade to 'Standard' or 'Premium'.",
+	"plan_trial_expired":   "Your trial has expired. Choose a plan:",
+	"plan_trial_active":    "You are on the trial plan. It expires on %s.",
+	"plan_none":            "You have no active subscription. Choose a plan:",
+	"plan_buy":             "Buy %s",
+	"plan_basic":           "Basic (30 days, 30 sessions)",
+	"plan_standard":        "Standard (60 days, 60 sessions)",
+	"plan_premium":         "Premium (90 days, unlimited)",
+	"subscription_invalid": "Invalid subscription choice.",
+	"subscription_error":   "Could not process the subscription.",
+	"subscription_done":    "You are subscribed to: %s",
+
+	"btn_verify_phone":   "📞 Confirm number",
+	"btn_send_location":  "📍 Send location",
+	"btn_create_apteka":  "Create pharmacy",
+	"btn_invite_manager": "Chain manager",
+	"btn_show":           "Show",
+	"btn_open_2gis":      "Open in 2GIS",
+	"btn_publish":        "Publish",
+	"btn_edit":           "Edit",
+	"btn_cancel":         "Cancel",
+	"btn_photos":         "Photos",
+	"btn_notify_new":     "🔔 Notify about new",
+	"btn_unsubscribe":    "Unsubscribe",
+	"btn_unpublish":      "Unpublish",
+	"btn_submit":         "Submit for review",
+	"btn_replace_photos": "Replace photos",
+	"btn_delete":         "Delete",
+	"btn_delete_confirm": "Yes, delete",
+	"btn_no":             "No",
+	"btn_done":           "Done",
+	"btn_edit_listing":   "Edit listing",
+	"btn_approve":        "✅ Approve",
+	"btn_reject":         "❌ Reject",
+	"btn_reject_other":   "✍️ Other reason",
+	"btn_back":           "◀️ Back",
+
+	"cmd_create_apteka": "Create a pharmacy",
+	"cmd_location":      "Share location",
+	"cmd_my_aptekas":    "My pharmacies",
+	"cmd_invite":        "Invite a pharmacist",
+	"cmd_houses":        "Find housing",
+	"cmd_my_listings":   "My listings",
+	"cmd_alerts":        "Listing subscriptions",
+	"cmd_language":      "Language",
+}
@@ -0,0 +1,134 @@
+// Package i18n holds the bot texts in Russian, Kyrgyz, Kazakh and English
+// and picks the plural form of a message by the number
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+const (
+	RU Lang = "ru"
+	KY Lang = "ky"
+	KK Lang = "kk"
+	EN Lang = "en"
+)
+
+// Default is used for unknown languages and for texts missing in a catalog
+const Default = RU
+
+// Langs are offered by /language in this order
+var Langs = []Lang{RU, KY, KK, EN}
+
+// Names are the languages named in themselves
+var Names = map[Lang]string{
+	RU: "Русский",
+	KY: "Кыргызча",
+	KK: "Қазақша",
+	EN: "English",
+}
+
+// Key identifies a message in the catalogs
+type Key string
+
+// Catalog maps keys to fmt formats, a plural message keeps its forms
+// separated by PluralSeparator in the order of the language plural rule
+type Catalog map[Key]string
+
+const PluralSeparator = "|"
+
+var catalogs = map[Lang]Catalog{
+	RU: ru,
+	KY: ky,
+	KK: kk,
+	EN: en,
+}
+
+// Parse reads a language code like "ru" or "en-US", ok is false for
+// unsupported languages
+func Parse(code string) (Lang, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	lang := Lang(base)
+	_, ok := catalogs[lang]
+	return lang, ok
+}
+
+// Match is the supported language for the Telegram language_code of a user
+func Match(code string) Lang {
+	if lang, ok := Parse(code); ok {
+		return lang
+	}
+	return Default
+}
+
+// T formats the message, a key missing in the catalog is taken from the
+// default one and an unknown key is returned as is
+func T(lang Lang, key Key, args ...any) string {
+	return format(message(lang, key), args)
+}
+
+// N formats the plural form of the message for n, n is not passed to the
+// format by itself
+func N(lang Lang, key Key, n int, args ...any) string {
+	forms := strings.Split(message(lang, key), PluralSeparator)
+	form := pluralForm(lang, n)
+	if form >= len(forms) {
+		form = len(forms) - 1
+	}
+	return format(forms[form], args)
+}
+
+// Has tells whether the key is in the default catalog
+func Has(key Key) bool {
+	_, ok := catalogs[Default][key]
+	return ok
+}
+
+func message(lang Lang, key Key) string {
+	if text, ok := catalogs[lang][key]; ok {
+		return text
+	}
+	if text, ok := catalogs[Default][key]; ok {
+		return text
+	}
+	return string(key)
+}
+
+func format(text string, args []any) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// PluralForms is the number of forms a plural message has in the language
+func PluralForms(lang Lang) int {
+	if lang == RU {
+		return 3
+	}
+	return 2
+}
+
+// pluralForm follows the CLDR cardinal rules: one, few and many for
+// Russian, one and other for the rest
+func pluralForm(lang Lang, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	if lang == RU {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	}
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestCatalogsComplete fails when a key is missing in any catalog or is
+// unknown to the default one
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Langs {
+		catalog, ok := catalogs[lang]
+		if !ok {
+			t.Errorf("no catalog for %s", lang)
+			continue
+		}
+		for key := range catalogs[Default] {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("%s: missing key %q", lang, key)
+			}
+		}
+		for key := range catalog {
+			if _, ok := catalogs[Default][key]; !ok {
+				t.Errorf("%s: key %q is not in the %s catalog", lang, key, Default)
+			}
+		}
+		if Names[lang] == "" {
+			t.Errorf("%s: no name", lang)
+		}
+	}
+}
+
+var verb = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z]`)
+
+// TestCatalogsFormat checks that translations take the same arguments and
+// plural messages have all the forms of the language
+func TestCatalogsFormat(t *testing.T) {
+	for key, text := range catalogs[Default] {
+		plural := strings.Contains(text, PluralSeparator)
+		want := verb.FindAllString(strings.Split(text, PluralSeparator)[0], -1)
+
+		for _, lang := range Langs {
+			forms := strings.Split(catalogs[lang][key], PluralSeparator)
+			switch {
+			case plural && len(forms) != PluralForms(lang):
+				t.Errorf("%s: %q has %d plural forms, want %d", lang, key, len(forms), PluralForms(lang))
+			case !plural && len(forms) != 1:
+				t.Errorf("%s: %q is not plural in %s", lang, key, Default)
+			}
+			for _, form := range forms {
+				if got := verb.FindAllString(form, -1); !slices.Equal(got, want) {
+					t.Errorf("%s: %q takes %v, want %v", lang, key, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestN(t *testing.T) {
+	tests := []struct {
+		lang Lang
+		n    int
+		want string
+	}{
+		{RU, 1, "1 комната"},
+		{RU, 3, "3 комнаты"},
+		{RU, 5, "5 комнат"},
+		{RU, 11, "11 комнат"},
+		{RU, 12, "12 комнат"},
+		{RU, 21, "21 комната"},
+		{RU, 22, "22 комнаты"},
+		{RU, 112, "112 комнат"},
+		{EN, 1, "1 room"},
+		{EN, 2, "2 rooms"},
+		{EN, 0, "0 rooms"},
+		{KY, 3, "3 бөлмө"},
+	}
+	for _, tt := range tests {
+		if got := N(tt.lang, "rooms", tt.n, tt.n); got != tt.want {
+			t.Errorf("N(%s, rooms, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(EN, "you_said", "hi"); got != "You said: “hi”" {
+		t.Errorf("T(en) = %q", got)
+	}
+	if got := T("uz", "open"); got != "Открыто" {
+		t.Errorf("T(uz) = %q, want the default catalog", got)
+	}
+	if got := T(EN, "no_such_key"); got != "no_such_key" {
+		t.Errorf("T(unknown key) = %q", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := map[string]Lang{
+		"ru":    RU,
+		"ky":    KY,
+		"kk":    KK,
+		"en":    EN,
+		"en-US": EN,
+		"KK":    KK,
+		"uz":    Default,
+		"":      Default,
+	}
+	for code, want := range tests {
+		if got := Match(code); got != want {
+			t.Errorf("Match(%q) = %s, want %s", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package i18n
+
+var kk = Catalog{
+	"default":              "Қандай дәрілерді іздеп жүрсіз? Атауларын жазыңыз немесе дауыстық хабар жіберіңіз 😊",
+	"voice_too_long":       "Дауыстық хабарыңыз тым ұзын",
+	"voice_not_recognized": "Дауыстық хабарды тану мүмкін болмады, қайталап көріңіз немесе мәтінмен жазыңыз",
+	"you_said":             "Сіз айттыңыз: «%s»",
+	"searching":            "Сәйкес дәріханаларды іздеп жатырмын",
+
+	"create_apteka":      "Дәріхана құру үшін төмендегі батырманы басыңыз",
+	"nothing_found":      "Өкінішке қарай, қажетті дәрілері бар дәріхана табылмады 😔",
+	"no_medicines":       "Дәрі атауларын түсіну мүмкін болмады, қайталап көріңіз",
+	"found_aptekas":      "Қажетті дәрілері бар %d дәріхана табылды|Қажетті дәрілері бар %d дәріхана табылды",
+	"did_you_mean":       "«%s» дегенді дұрыс түсіндім бе, сенімді емеспін. Мұны айтқыңыз келді ме:",
+	"suggestion_expired": "Бұл іздеу ескірді, қайта іздеңіз",
+	"not_in_catalog":     "Анықтамалықтан табылмады: %s",
+	"distance_m":         "%d м",
+	"distance_km":        "%s км",
+
+	"not_pharmacist":       "Қалдықтарды тек дәріхана қызметкерлері жүктей алады",
+	"stock_file_too_big":   "Файл тым үлкен, ең үлкен көлемі - 20 МБ",
+	"stock_processing":     "Қалдықтар файлын өңдеп жатырмын",
+	"stock_unsupported":    "1С-тен XML (CommerceML), CSV және XLSX форматындағы жүктемелерге қолдау көрсетіледі",
+	"stock_broken":         "Файлды оқу мүмкін болмады, жүктеме форматын тексеріңіз",
+	"stock_updated":        "База жаңартылды ✅",
+	"stock_imported":       "Жүктелген позициялар: %d",
+	"stock_unmatched":      "Анықтамалықтан табылмады: %d",
+	"stock_errors":         "Қателер: %d",
+	"stock_line":           "%d-жол",
+	"stock_error_quantity": "саны қате: %q",
+	"stock_choose_apteka":  "Қалдықтарды қай дәріханаға жүктейміз?",
+	"stock_expired":        "Файл ескірді, оны қайта жіберіңіз",
+
+	"apteka_invite":       "Филиалдың дәріханашыларын /invite командасымен шақырыңыз",
+	"apteka_created":      "Дәріхана сәтті құрылды! ✅",
+	"apteka_upload_stock": "Енді 1С-тен жүктелген дәрі қалдықтары файлын жіберіңіз",
+	"apteka_pending":      "Өтінім әкімшіге жіберілді. Тексерістен кейін дәріхана іздеуде пайда болады, ал сіз оның дәріханашысы боласыз ⏳",
+	"apteka_review":       "🆕 Тексеруге жаңа дәріхана",
+	"apteka_approved":     "Дәріхана тексерістен өтті! ✅",
+	"apteka_rejected":     "Дәріхана тексерістен өтпеді ❌",
+	"apteka_refused":      "❌ Қабылданбады",
+	"apteka_reviewed":     "Бұл дәріхана тексеріліп қойған",
+	"no_access":           "Бұл дәріханаға қолжетімділігіңіз жоқ",
+	"no_aptekas":          "Сізде әзірге дәріхана жоқ, оны /create_apteka командасымен құрыңыз",
+	"invite_who":          "Кімді шақырғыңыз келеді?",
+	"invite_created":      "Бұл сілтемені қызметкерге жіберіңіз, ол 7 күн ішінде тек бір рет жұмыс істейді:",
+	"invite_invalid":      "Шақыру жарамсыз немесе пайдаланылып қойған",
+	"joined_apteka":       "Сіз дәріханаға дәріханашы ретінде қосылдыңыз ✅",
+	"joined_chain":        "Сіз дәріханалар желісінің басқарушысы болып тағайындалдыңыз ✅\n\nФилиалдарды /create_apteka командасымен құрып, дәріханашыларды /invite командасымен шақырыңыз",
+	"create_chain_usage":  "Желінің атауын көрсетіңіз: /create_chain Атауы",
+
+	"ask_location":   "Геолокацияңызды жіберіңіз, мен жақын дәріханаларды көрсетемін",
+	"location_saved": "Геолокация сақталды 📍 Енді дәріханалар қашықтығы бойынша сұрыпталады",
+
+	"open_24x7":       "Тәулік бойы ашық",
+	"open_until":      "%s дейін ашық",
+	"open":            "Ашық",
+	"closed":          "Жабық",
+	"closed_opens_at": "Жабық, сағат %s ашылады",
+	"closed_opens_on": "Жабық, %s сағат %s ашылады",
+	"weekday_sun":     "Жс",
+	"weekday_mon":     "Дс",
+	"weekday_tue":     "Сс",
+	"weekday_wed":     "Ср",
+	"weekday_thu":     "Бс",
+	"weekday_fri":     "Жм",
+	"weekday_sat":     "Сн",
+	"inventory_never": "қалдықтар әлі жүктелмеген",
+	"inventory_today": "қалдықтар бүгін жаңартылды",
+	"inventory_days":  "қалдықтар %d күн бұрын жаңартылды|қалдықтар %d күн бұрын жаңартылды",
+	"inventory_reminder": "⏰ «%s» дәріханасының қалдықтарын жаңартатын уақыт келді, %s: %s.\n\n" +
+		"База жаңартылғанша дәріхана іздеуде басқалардан төмен көрсетіледі. " +
+		"1С-тен жүктелген қалдықтар файлын жіберіңіз",
+	"inventory_escalation": "⚠️ «%s» филиалы, %s қалдықтарды жаңартпайды: %s, жауапсыз ескертулер: %d",
+
+	"webapp_error":      "Бірдеңе дұрыс болмады - қайталап көріңіз",
+	"validation_errors": "Формадағы деректерді тексеріңіз:",
+	"label_name":        "Атауы",
+	"label_city":        "Қала",
+	"label_address":     "Мекенжай",
+	"label_phone":       "Телефон",
+	"label_room_count":  "Бөлмелер саны",
+	"label_price":       "Бағасы",
+	"label_work_hours":  "Жұмыс уақыты",
+	"label_location":    "Геолокация",
+	"label_rooms_min":   "Бөлме, мин.",
+	"label_rooms_max":   "Бөлме, макс.",
+	"label_price_min":   "Баға, мин.",
+	"label_price_max":   "Баға, макс.",
+	"label_sort":        "Сұрыптау",
+	"label_page":        "Бет",
+	"label_limit":       "Беттегі хабарландырулар",
+
+	"error_required":           "міндетті өріс",
+	"error_too_short":          "кемінде %d таңба",
+	"error_too_long":           "%d таңбадан аспауы керек",
+	"error_range":              "%d-ден %d-ге дейін",
+	"error_format":             "қате формат",
+	"error_type":               "мән түрі қате",
+	"error_invalid":            "қате мән",
+	"error_phone_format":       "Қырғызстан, Қазақстан немесе Ресей нөмірі, мысалы 0555 123 456",
+	"error_work_hours_format":  "«Пн-Пт 9:00-21:00, Сб 10:00-18:00» түрінде жазыңыз",
+	"error_work_hours_invalid": "уақыт белдеуі немесе күн қате",
+	"error_location_invalid":   "координаттар қате",
+	"error_sort_format":        "new, price, -price, rooms ішінен біреуі",
+
+	"photo_unsupported": "Фотоны ашу мүмкін болмады, JPEG, PNG немесе WebP форматындағы сурет жіберіңіз",
+	"photo_too_big":     "Фото тым үлкен, %d МБ-тан аспауы керек",
+	"too_many_photos":   "Хабарландыруға %d фотодан артық тіркеуге болмайды",
+	"photo_as_file":     "Хабарландыруға фото тіркеу үшін оны файл емес, фото ретінде жіберіңіз",
+
+	"house_preview":        "Жарияламас бұрын хабарландыруды тексеріңіз:",
+	"house_edit":           "Формадағы деректерді түзетіп, қайта жіберіңіз, фотолар сақталды",
+	"house_cancelled":      "Хабарландыру жойылды",
+	"house_not_found":      "Хабарландыру табылмады немесе жарияланып қойған",
+	"house_unavailable":    "Хабарландыру енді қолжетімсіз",
+	"houses_not_found":     "Сұрауыңыз бойынша хабарландыру жоқ 😔\n\nІздеу үлгісі: /houses Алматы 2-3",
+	"house_search_expired": "Іздеу ескірді, /houses командасын қайталаңыз",
+	"houses_page":          "%d хабарландыру табылды, %d/%d бет|%d хабарландыру табылды, %d/%d бет",
+	"rooms":                "%d бөлме|%d бөлме",
+	"photos":               "%d фото|%d фото",
+	"price":                "%s сом",
+	"price_negotiable":     "Бағасы келісім бойынша",
+	"bounds_from":          "%d бастап",
+	"bounds_to":            "%d дейін",
+
+	"no_listings":             "Сізде әзірге хабарландыру жоқ",
+	"status_pending":          "⏳ Модерацияда",
+	"status_rejected":         "❌ Қабылданбады: %s",
+	"status_published":        "✅ Жарияланды",
+	"status_unpublished":      "⏸ Жариялаудан алынды",
+	"status_draft":            "📝 Жоба",
+	"listing_edit":            "Төмендегі батырманы басып, деректерді түзетіп, форманы жіберіңіз",
+	"listing_updated":         "Хабарландыру жаңартылды ✅",
+	"listing_send_photos":     "Жаңа фотоларды жіберіңіз, олар қазіргілерін ауыстырады. Аяқтағанда «Дайын» батырмасын басыңыз",
+	"listing_no_photos":       "Сіз әлі бірде-бір фото жібермедіңіз",
+	"listing_photos_replaced": "Фотолар жаңартылды ✅",
+
+	"verify_phone":      "Телефон нөміріңізді растаңыз: расталған нөмірі бар хабарландырулар модерациядан тезірек өтеді. Хабарландырудағы нөмір Telegram нөміріңізбен сәйкес келуі керек",
+	"phone_verified":    "Нөмір расталды ✅",
+	"contact_not_yours": "Өз контактіңізді «Нөмірді растау» батырмасымен жіберіңіз",
+	"phone_unsupported": "Тек Қырғызстан, Қазақстан және Ресей нөмірлеріне қолдау көрсетіледі",
+	"phone_mismatch":    "%s нөмірі сақталды, бірақ ол хабарландыруларыңыздағы нөмірмен сәйкес келмейді",
+
+	"house_submitted":            "Хабарландыру модерацияға жіберілді ⏳ Тексерілген соң хабарлаймыз",
+	"house_approved":             "Хабарландыру модерациядан өтіп, жарияланды ✅",
+	"house_rejected":             "Хабарландыруды модератор қабылдамады ❌\nСебебі: %s\n\nОны /my_listings бөлімінде түзетіп, қайта жіберіңіз",
+	"moderation_new":             "🆕 Модерацияға хабарландыру",
+	"moderation_approved":        "✅ Мақұлданды",
+	"moderation_rejected":        "❌ Қабылданбады: %s",
+	"moderation_done":            "Бұл хабарландыру тексеріліп қойған",
+	"moderation_ask_reason":      "Қабылдамау себебін осы хабарламаға жауап ретінде жазыңыз",
+	"moderation_reason_too_long": "Себеп тым ұзын, %d таңбадан аспасын",
+	"reject_phone":               "Телефон нөмірі қате",
+	"reject_duplicate":           "Қайталанған хабарландыру",
+	"reject_content":             "Рұқсат етілмеген мазмұн",
+	"reject_photos":              "Жарамсыз фотолар",
+	"reject_address":             "Мекенжай толық емес немесе қате",
+	"flag_banned_word":           "Тыйым салынған сөздер",
+	"flag_unverified_phone":      "Телефон нөмірін иесі растамаған",
+	"flag_duplicate_address":     "Бұл мекенжай басқа хабарландыруларда бар",
+	"flag_duplicate_phone":       "Телефон басқа иесінің хабарландыруларында көрсетілген",
+
+	"alert_saved":     "Дайын 🔔 Сәйкес хабарландыру шыққанда хабарлаймын. Жазылымдар: /alerts",
+	"alert_deleted":   "Жазылым жойылды",
+	"too_many_alerts": "Сізде %d жазылым бар, артығын /alerts командасымен жойыңыз",
+	"no_alerts":       "Сізде жазылым жоқ. Тұрғын үйді /houses командасымен іздеп, «Жаңалары туралы хабарлау» батырмасын басыңыз",
+	"alerts":          "Жазылымдарыңыз, жазылымнан бас тарту үшін басыңыз:",
+	"all_houses":      "Барлық хабарландырулар",
+	"new_house":       "🔔 Жазылымыңыз бойынша жаңа хабарландыру",
+
+	"language_choose":  "Тілді таңдаңыз:",
+	"language_changed": "Тіл өзгертілді ✅",
+
+	"session_not_allowed":  "Сессияны бастай алмайсыз. Мүмкін, жазылымыңыз жоқ.",
+	"session_topic":        "%d-тақырып",
+	"session_choose_topic": "Тақырыпты таңдаңыз:",
+	"session_rate":         "Сессияны 1-ден 5-ке дейін бағалаңыз:",
+	"session_topic_first":  "Алдымен тақырыпты таңдаңыз, содан кейін хабар жаза аласыз.",
+	"session_topic_needed": "Алдымен тақырыпты таңдаңыз.",
+	"session_last_closed":  "Соңғы сессия жабылды. Жаңа диалог бастай аласыз.",
+	"session_closed":       "Сессия жабық. Жаңа диалог бастай аласыз.",
+	"session_limit":        "Хабарлар лимиті таусылды. Жаңа диалог бастай аласыз.",
+	"session_none":         "Сізде белсенді сессия жоқ.",
+	"session_finished":     "Сессия аяқталды. Жаңа диалог бастай аласыз.",
+	"session_trial":        "Сынақ кезеңі іске қосылды! Сізде 2 сессия бар.",
+	"session_expired":      "Жазылымыңыздың мерзімі бітті. Жаңасын сатып алыңыз",
+	"session_unlimited":    "Сізде шексіз сессиялар бар.",
+	"session_no_left":      "Сізде сессия қалмады. Жаңасын сатып алыңыз",
+	"session_allowed":      "Сессияны бастай аласыз.",
+	"server_error":         "Сервер қатесі, кейінірек қайталап көріңіз.",
+	"support_prompt":       "Қолдау қызметіне хабарыңызды жіберіңіз:",
+	"support_sent":         "Хабарыңыз қолдау қызметіне жіберілді.",
+	"feedback_prompt":      "Бот туралы пікіріңізді жіберіңіз:",
+	"feedback_thanks":      "Рахмет, пікіріңіз біз үшін өте маңызды.",
+
+	"user_not_found":       "Қате: пайдаланушы табылмады.",
+	"plan_premium_active":  "Сізде 'Premium' тарифі белсенді. Мерзімі: %s.\nЖазылымды мерзімі біткен соң ұзарта аласыз.",
+	"plan_standard_active": "Сізде 'Standard' тарифі белсенді. 'Premium' тарифіне өтуге болады.",
+	"plan_basic_active":    "Сізде 'Basic' тарифі белсенді. 'Standard' немесе 'Premium' тарифіне өтуге болады.",
+	"plan_trial_expired":   "Сынақ кезеңіңіз бітті. Тарифті таңдаңыз:",
+	"plan_trial_active":    "Сіз сынақ тарифін пайдаланып жатырсыз. Мерзімі: %s.",
+	"plan_none":            "Сізде белсенді жазылым жоқ. Тарифті таңдаңыз:",
+	"plan_buy":             "%s сатып алу",
+	"plan_basic":           "Basic (30 күн, 30 сессия)",
+	"plan_standard":        "Standard (60 күн, 60 сессия)",
+	"plan_premium":         "Premium (90 күн, шексіз)",
+	"subscription_invalid": "Жазылым дұрыс таңдалмады.",
+	"subscription_error":   "Жазылымды рәсімдеу кезінде қате шықты.",
+	"subscription_done":    "Жазылым сәтті рәсімделді: %s",
+
+	"btn_verify_phone":   "📞 Нөмірді растау",
+	"btn_send_location":  "📍 Геолокацияны жіберу",
+	"btn_create_apteka":  "Дәріхана құру",
+	"btn_invite_manager": "Желі басқарушысын",
+	"btn_show":           "Қарау",
+	"btn_open_2gis":      "2ГИС-те ашу",
+	"btn_publish":        "Жариялау",
+	"btn_edit":           "Өзгерту",
+	"btn_cancel":         "Болдырмау",
+	"btn_photos":         "Фото",
+	"btn_notify_new":     "🔔 Жаңалары туралы хабарлау",
+	"btn_unsubscribe":    "Жазылымнан бас тарту",
+	"btn_unpublish":      "Жариялаудан алу",
+	"btn_submit":         "Модерацияға жіберу",
+	"btn_replace_photos": "Фотоны ауыстыру",
+	"btn_delete":         "Жою",
+	"btn_delete_confirm": "Иә, жою",
+	"btn_no":             "Жоқ",
+	"btn_done":           "Дайын",
+	"btn_edit_listing":   "Хабарландыруды өзгерту",
+	"btn_approve":        "✅ Мақұлдау",
+	"btn_reject":         "❌ Қабылдамау",
+	"btn_reject_other":   "✍️ Басқа себеп",
+	"btn_back":           "◀️ Артқа",
+
+	"cmd_create_apteka": "Дәріхана құру",
+	"cmd_location":      "Геолокацияны көрсету",
+	"cmd_my_aptekas":    "Менің дәріханаларым",
+	"cmd_invite":        "Дәріханашыны шақыру",
+	"cmd_houses":        "Тұрғын үй іздеу",
+	"cmd_my_listings":   "Менің хабарландыруларым",
+	"cmd_alerts":        "Хабарландыруларға жазылымдар",
+	"cmd_language":      "Тіл",
+}
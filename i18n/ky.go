@@ -0,0 +1,245 @@
+package i18n
+
+var ky = Catalog{
+	"default":              "Кайсы дары-дармектерди издеп жатасыз? Аттарын жазыңыз же үн кабар жөнөтүңүз 😊",
+	"voice_too_long":       "Үн кабарыңыз өтө узун",
+	"voice_not_recognized": "Үн кабарды тааный алган жокпуз, кайра аракет кылыңыз же текст менен жазыңыз",
+	"you_said":             "Сиз айттыңыз: «%s»",
+	"searching":            "Ылайыктуу дарыканаларды издеп жатам",
+
+	"create_apteka":      "Дарыкана түзүү үчүн төмөнкү баскычты басыңыз",
+	"nothing_found":      "Тилекке каршы, керектүү дарылар бар дарыкана табылган жок 😔",
+	"no_medicines":       "Дарылардын аттарын түшүнө алган жокпуз, кайра аракет кылыңыз",
+	"found_aptekas":      "Керектүү дарылар бар %d дарыкана табылды|Керектүү дарылар бар %d дарыкана табылды",
+	"did_you_mean":       "«%s» дегенди туура түшүндүмбү, билбейм. Сиз муну айткыңыз келдиби:",
+	"suggestion_expired": "Бул издөө эскирди, кайра издеңиз",
+	"not_in_catalog":     "Маалымдамадан табылган жок: %s",
+	"distance_m":         "%d м",
+	"distance_km":        "%s км",
+
+	"not_pharmacist":       "Калдыктарды дарыкана кызматкерлери гана жүктөй алат",
+	"stock_file_too_big":   "Файл өтө чоң, эң чоң өлчөмү - 20 МБ",
+	"stock_processing":     "Калдыктар файлын иштетип жатам",
+	"stock_unsupported":    "1С-тен XML (CommerceML), CSV жана XLSX форматындагы жүктөмөлөр колдоого алынат",
+	"stock_broken":         "Файлды окуй алган жокпуз, жүктөмөнүн форматын текшериңиз",
+	"stock_updated":        "База жаңыртылды ✅",
+	"stock_imported":       "Жүктөлгөн позициялар: %d",
+	"stock_unmatched":      "Маалымдамадан табылган жок: %d",
+	"stock_errors":         "Каталар: %d",
+	"stock_line":           "%d-сап",
+	"stock_error_quantity": "саны туура эмес: %q",
+	"stock_choose_apteka":  "Калдыктарды кайсы дарыканага жүктөйлү?",
+	"stock_expired":        "Файл эскирди, аны кайра жөнөтүңүз",
+
+	"apteka_invite":       "Филиалдын дарыкана кызматкерлерин /invite буйругу менен чакырыңыз",
+	"apteka_created":      "Дарыкана ийгиликтүү түзүлдү! ✅",
+	"apteka_upload_stock": "Эми 1С-тен жүктөлгөн дары калдыктарынын файлын жөнөтүңүз",
+	"apteka_pending":      "Өтүнмө администраторго жөнөтүлдү. Текшерүүдөн кийин дарыкана издөөдө пайда болот, ал эми сиз анын кызматкери болосуз ⏳",
+	"apteka_review":       "🆕 Текшерүүгө жаңы дарыкана",
+	"apteka_approved":     "Дарыкана текшерүүдөн өттү! ✅",
+	"apteka_rejected":     "Дарыкана текшерүүдөн өткөн жок ❌",
+	"apteka_refused":      "❌ Четке кагылды",
+	"apteka_reviewed":     "Бул дарыкана текшерилип бүткөн",
+	"no_access":           "Бул дарыканага мүмкүнчүлүгүңүз жок",
+	"no_aptekas":          "Сизде азырынча дарыкана жок, аны /create_apteka буйругу менен түзүңүз",
+	"invite_who":          "Кимди чакыргыңыз келет?",
+	"invite_created":      "Бул шилтемени кызматкерге жөнөтүңүз, ал 7 күн ичинде бир гана жолу иштейт:",
+	"invite_invalid":      "Чакыруу жараксыз же колдонулуп бүткөн",
+	"joined_apteka":       "Сиз дарыканага кызматкер катары кошулдуңуз ✅",
+	"joined_chain":        "Сиз дарыканалар тармагынын башкаруучусу болуп дайындалдыңыз ✅\n\nФилиалдарды /create_apteka буйругу менен түзүп, кызматкерлерди /invite буйругу менен чакырыңыз",
+	"create_chain_usage":  "Тармактын атын жазыңыз: /create_chain Аты",
+
+	"ask_location":   "Жайгашкан жериңизди жөнөтүңүз, жакынкы дарыканаларды көрсөтөм",
+	"location_saved": "Жайгашкан жер сакталды 📍 Эми дарыканалар аралыгы боюнча иреттелет",
+
+	"open_24x7":       "Күнү-түнү ачык",
+	"open_until":      "%s чейин ачык",
+	"open":            "Ачык",
+	"closed":          "Жабык",
+	"closed_opens_at": "Жабык, саат %s ачылат",
+	"closed_opens_on": "Жабык, %s саат %s ачылат",
+	"weekday_sun":     "Жк",
+	"weekday_mon":     "Дш",
+	"weekday_tue":     "Шш",
+	"weekday_wed":     "Шр",
+	"weekday_thu":     "Бш",
+	"weekday_fri":     "Жм",
+	"weekday_sat":     "Иш",
+	"inventory_never": "калдыктар али жүктөлгөн эмес",
+	"inventory_today": "калдыктар бүгүн жаңыртылды",
+	"inventory_days":  "калдыктар %d күн мурун жаңыртылды|калдыктар %d күн мурун жаңыртылды",
+	"inventory_reminder": "⏰ «%s» дарыканасынын калдыктарын жаңыртуу убактысы келди, %s: %s.\n\n" +
+		"База жаңыртылганга чейин дарыкана издөөдө башкалардан төмөн көрсөтүлөт. " +
+		"1С-тен жүктөлгөн калдыктар файлын жөнөтүңүз",
+	"inventory_escalation": "⚠️ «%s» филиалы, %s калдыктарды жаңыртпай жатат: %s, жооп берилбеген эскертмелер: %d",
+
+	"webapp_error":      "Бир нерсе туура эмес болду - кайра аракет кылыңыз",
+	"validation_errors": "Формадагы маалыматтарды текшериңиз:",
+	"label_name":        "Аталышы",
+	"label_city":        "Шаар",
+	"label_address":     "Дарек",
+	"label_phone":       "Телефон",
+	"label_room_count":  "Бөлмөлөрдүн саны",
+	"label_price":       "Баасы",
+	"label_work_hours":  "Иш убактысы",
+	"label_location":    "Жайгашкан жери",
+	"label_rooms_min":   "Бөлмө, мин.",
+	"label_rooms_max":   "Бөлмө, макс.",
+	"label_price_min":   "Баасы, мин.",
+	"label_price_max":   "Баасы, макс.",
+	"label_sort":        "Иреттөө",
+	"label_page":        "Барак",
+	"label_limit":       "Бир барактагы жарнамалар",
+
+	"error_required":           "милдеттүү талаа",
+	"error_too_short":          "кеминде %d белги",
+	"error_too_long":           "%d белгиден ашпашы керек",
+	"error_range":              "%d дан %d чейин",
+	"error_format":             "туура эмес формат",
+	"error_type":               "маанинин түрү туура эмес",
+	"error_invalid":            "туура эмес маани",
+	"error_phone_format":       "Кыргызстандын, Казакстандын же Россиянын номери, мисалы 0555 123 456",
+	"error_work_hours_format":  "«Пн-Пт 9:00-21:00, Сб 10:00-18:00» түрүндө жазыңыз",
+	"error_work_hours_invalid": "убакыт алкагы же дата туура эмес",
+	"error_location_invalid":   "координаттар туура эмес",
+	"error_sort_format":        "new, price, -price, rooms ичинен бири",
+
+	"photo_unsupported": "Сүрөттү ача алган жокпуз, JPEG, PNG же WebP форматындагы сүрөт жөнөтүңүз",
+	"photo_too_big":     "Сүрөт өтө чоң, %d МБдан ашпашы керек",
+	"too_many_photos":   "Жарнамага %d сүрөттөн ашык тиркөөгө болбойт",
+	"photo_as_file":     "Жарнамага сүрөт тиркөө үчүн аны файл эмес, сүрөт катары жөнөтүңүз",
+
+	"house_preview":        "Жарыялоодон мурун жарнаманы текшериңиз:",
+	"house_edit":           "Формадагы маалыматтарды оңдоп, кайра жөнөтүңүз, сүрөттөр сакталды",
+	"house_cancelled":      "Жарнама өчүрүлдү",
+	"house_not_found":      "Жарнама табылган жок же жарыяланып калган",
+	"house_unavailable":    "Жарнама мындан ары жеткиликсиз",
+	"houses_not_found":     "Сурамыңыз боюнча жарнама жок 😔\n\nИздөөнүн үлгүсү: /houses Бишкек 2-3",
+	"house_search_expired": "Издөө эскирди, /houses буйругун кайталаңыз",
+	"houses_page":          "%d жарнама табылды, %d/%d барак|%d жарнама табылды, %d/%d барак",
+	"rooms":                "%d бөлмө|%d бөлмө",
+	"photos":               "%d сүрөт|%d сүрөт",
+	"price":                "%s сом",
+	"price_negotiable":     "Баасы келишим боюнча",
+	"bounds_from":          "%d баштап",
+	"bounds_to":            "%d чейин",
+
+	"no_listings":             "Сизде азырынча жарнама жок",
+	"status_pending":          "⏳ Модерацияда",
+	"status_rejected":         "❌ Четке кагылды: %s",
+	"status_published":        "✅ Жарыяланды",
+	"status_unpublished":      "⏸ Жарыялоодон алынды",
+	"status_draft":            "📝 Долбоор",
+	"listing_edit":            "Төмөнкү баскычты басып, маалыматтарды оңдоп, форманы жөнөтүңүз",
+	"listing_updated":         "Жарнама жаңыртылды ✅",
+	"listing_send_photos":     "Жаңы сүрөттөрдү жөнөтүңүз, алар учурдагыларды алмаштырат. Бүткөндө «Даяр» баскычын басыңыз",
+	"listing_no_photos":       "Сиз азырынча бир да сүрөт жөнөткөн жоксуз",
+	"listing_photos_replaced": "Сүрөттөр жаңыртылды ✅",
+
+	"verify_phone":      "Телефон номериңизди ырастаңыз: ырасталган номери бар жарнамалар модерациядан тезирээк өтөт. Жарнамадагы номер Telegram номериңиз менен дал келиши керек",
+	"phone_verified":    "Номер ырасталды ✅",
+	"contact_not_yours": "Өз байланышыңызды «Номерди ырастоо» баскычы менен жөнөтүңүз",
+	"phone_unsupported": "Кыргызстан, Казакстан жана Россиянын номерлери гана колдоого алынат",
+	"phone_mismatch":    "%s номери сакталды, бирок ал жарнамаларыңыздагы номер менен дал келбейт",
+
+	"house_submitted":            "Жарнама модерацияга жөнөтүлдү ⏳ Текшерилгенде кабарлайбыз",
+	"house_approved":             "Жарнама модерациядан өтүп, жарыяланды ✅",
+	"house_rejected":             "Жарнаманы модератор четке какты ❌\nСебеби: %s\n\nАны /my_listings бөлүмүндө оңдоп, кайра жөнөтүңүз",
+	"moderation_new":             "🆕 Модерацияга жарнама",
+	"moderation_approved":        "✅ Жактырылды",
+	"moderation_rejected":        "❌ Четке кагылды: %s",
+	"moderation_done":            "Бул жарнама текшерилип бүткөн",
+	"moderation_ask_reason":      "Четке кагуу себебин ушул билдирүүгө жооп катары жазыңыз",
+	"moderation_reason_too_long": "Себеп өтө узун, %d белгиден ашпасын",
+	"reject_phone":               "Телефон номери туура эмес",
+	"reject_duplicate":           "Кайталанган жарнама",
+	"reject_content":             "Жол берилгис мазмун",
+	"reject_photos":              "Ылайыксыз сүрөттөр",
+	"reject_address":             "Дарек толук эмес же туура эмес",
+	"flag_banned_word":           "Тыюу салынган сөздөр",
+	"flag_unverified_phone":      "Телефон номерин ээси ырастаган эмес",
+	"flag_duplicate_address":     "Бул дарек башка жарнамаларда бар",
+	"flag_duplicate_phone":       "Телефон башка ээнин жарнамаларында көрсөтүлгөн",
+
+	"alert_saved":     "Даяр 🔔 Ылайыктуу жарнама чыкканда кабарлайм. Жазылуулар: /alerts",
+	"alert_deleted":   "Жазылуу өчүрүлдү",
+	"too_many_alerts": "Сизде %d жазылуу бар, ашыктарын /alerts буйругу менен өчүрүңүз",
+	"no_alerts":       "Сизде жазылуу жок. Турак жайды /houses буйругу менен издеп, «Жаңылары тууралуу кабарлоо» баскычын басыңыз",
+	"alerts":          "Жазылууларыңыз, жазылуудан баш тартуу үчүн басыңыз:",
+	"all_houses":      "Бардык жарнамалар",
+	"new_house":       "🔔 Жазылууңуз боюнча жаңы жарнама",
+
+	"language_choose":  "Тилди тандаңыз:",
+	"language_changed": "Тил өзгөртүлдү ✅",
+
+	"session_not_allowed":  "Сессияны баштай албайсыз. Балким, жазылууңуз жок.",
+	"session_topic":        "%d-тема",
+	"session_choose_topic": "Теманы тандаңыз:",
+	"session_rate":         "Сессияны 1ден 5ке чейин баалаңыз:",
+	"session_topic_first":  "Адегенде теманы тандаңыз, андан кийин кабар жаза аласыз.",
+	"session_topic_needed": "Адегенде теманы тандаңыз.",
+	"session_last_closed":  "Акыркы сессия жабылды. Жаңы диалог баштай аласыз.",
+	"session_closed":       "Сессия жабык. Жаңы диалог баштай аласыз.",
+	"session_limit":        "Кабарлардын лимити бүттү. Жаңы диалог баштай аласыз.",
+	"session_none":         "Сизде активдүү сессия жок.",
+	"session_finished":     "Сессия аяктады. Жаңы диалог баштай аласыз.",
+	"session_trial":        "Сыноо мезгили иштетилди! Сизде 2 сессия бар.",
+	"session_expired":      "Жазылууңуздун мөөнөтү бүттү. Жаңысын сатып алыңыз",
+	"session_unlimited":    "Сизде чексиз сессиялар бар.",
+	"session_no_left":      "Сизде сессия калган жок. Жаңысын сатып алыңыз",
+	"session_allowed":      "Сессияны баштай аласыз.",
+	"server_error":         "Сервердин катасы, кийинчерээк аракет кылыңыз.",
+	"support_prompt":       "Колдоо кызматына кабарыңызды жөнөтүңүз:",
+	"support_sent":         "Кабарыңыз колдоо кызматына жөнөтүлдү.",
+	"feedback_prompt":      "Бот тууралуу пикириңизди жөнөтүңүз:",
+	"feedback_thanks":      "Рахмат, пикириңиз биз үчүн абдан маанилүү.",
+
+	"user_not_found":       "Ката: колдонуучу табылган жок.",
+	"plan_premium_active":  "Сизде 'Premium' тарифи активдүү. Мөөнөтү: %s.\nЖазылууну мөөнөтү бүткөндөн кийин узарта аласыз.",
+	"plan_standard_active": "Сизде 'Standard' тарифи активдүү. 'Premium' тарифине өтсөңүз болот.",
+	"plan_basic_active":    "Сизде 'Basic' тарифи активдүү. 'Standard' же 'Premium' тарифине өтсөңүз болот.",
+	"plan_trial_expired":   "Сыноо мезгилиңиз бүттү. Тарифти тандаңыз:",
+	"plan_trial_active":    "Сиз сыноо тарифин колдонуп жатасыз. Мөөнөтү: %s.",
+	"plan_none":            "Сизде активдүү жазылуу жок. Тарифти тандаңыз:",
+	"plan_buy":             "%s сатып алуу",
+	"plan_basic":           "Basic (30 күн, 30 сессия)",
+	"plan_standard":        "Standard (60 күн, 60 сессия)",
+	"plan_premium":         "Premium (90 күн, чексиз)",
+	"subscription_invalid": "Жазылуу туура эмес тандалды.",
+	"subscription_error":   "Жазылууну тариздөөдө ката кетти.",
+	"subscription_done":    "Жазылуу ийгиликтүү таризделди: %s",
+
+	"btn_verify_phone":   "📞 Номерди ырастоо",
+	"btn_send_location":  "📍 Жайгашкан жерди жөнөтүү",
+	"btn_create_apteka":  "Дарыкана түзүү",
+	"btn_invite_manager": "Тармактын башкаруучусун",
+	"btn_show":           "Көрүү",
+	"btn_open_2gis":      "2ГИСте ачуу",
+	"btn_publish":        "Жарыялоо",
+	"btn_edit":           "Өзгөртүү",
+	"btn_cancel":         "Жокко чыгаруу",
+	"btn_photos":         "Сүрөттөр",
+	"btn_notify_new":     "🔔 Жаңылары тууралуу кабарлоо",
+	"btn_unsubscribe":    "Жазылуудан баш тартуу",
+	"btn_unpublish":      "Жарыялоодон алуу",
+	"btn_submit":         "Модерацияга жөнөтүү",
+	"btn_replace_photos": "Сүрөттөрдү алмаштыруу",
+	"btn_delete":         "Өчүрүү",
+	"btn_delete_confirm": "Ооба, өчүрүү",
+	"btn_no":             "Жок",
+	"btn_done":           "Даяр",
+	"btn_edit_listing":   "Жарнаманы өзгөртүү",
+	"btn_approve":        "✅ Жактыруу",
+	"btn_reject":         "❌ Четке кагуу",
+	"btn_reject_other":   "✍️ Башка себеп",
+	"btn_back":           "◀️ Артка",
+
+	"cmd_create_apteka": "Дарыкана түзүү",
+	"cmd_location":      "Жайгашкан жерди көрсөтүү",
+	"cmd_my_aptekas":    "Менин дарыканаларым",
+	"cmd_invite":        "Кызматкерди чакыруу",
+	"cmd_houses":        "Турак жай издөө",
+	"cmd_my_listings":   "Менин жарнамаларым",
+	"cmd_alerts":        "Жарнамаларга жазылуулар",
+	"cmd_language":      "Тил",
+}
@@ -0,0 +1,245 @@
+package i18n
+
+var ru = Catalog{
+	"default":              "Какие лекарства Вы ищете? Напишите их названия или просто запишите голосовое 😊",
+	"voice_too_long":       "Вы отправили слишком длинное голосовое сообщение",
+	"voice_not_recognized": "Не удалось распознать голосовое сообщение, попробуйте еще раз или напишите текстом",
+	"you_said":             "Вы сказали: «%s»",
+	"searching":            "Ищу подходящие аптеки",
+
+	"create_apteka":      "Чтобы создать аптеку нажмите кнопку ниже",
+	"nothing_found":      "К сожалению, не нашли аптек с нужными Вам лекарствами 😔",
+	"no_medicines":       "Не удалось разобрать названия лекарств, попробуйте еще раз",
+	"found_aptekas":      "Найдена %d аптека, которая содержит нужные Вам лекарства|Найдено %d аптеки, которые содержат нужные Вам лекарства|Найдено %d аптек, которые содержат нужные Вам лекарства",
+	"did_you_mean":       "Не уверен, что правильно понял «%s». Вы имели в виду:",
+	"suggestion_expired": "Этот поиск устарел, повторите запрос",
+	"not_in_catalog":     "Не нашел в справочнике: %s",
+	"distance_m":         "%d м",
+	"distance_km":        "%s км",
+
+	"not_pharmacist":       "Загружать остатки могут только аптекари",
+	"stock_file_too_big":   "Файл слишком большой, максимальный размер - 20 МБ",
+	"stock_processing":     "Обрабатываю файл с остатками",
+	"stock_unsupported":    "Поддерживаются выгрузки из 1С в форматах XML (CommerceML), CSV и XLSX",
+	"stock_broken":         "Не удалось прочитать файл, проверьте формат выгрузки",
+	"stock_updated":        "База обновлена ✅",
+	"stock_imported":       "Импортировано позиций: %d",
+	"stock_unmatched":      "Не найдено в справочнике: %d",
+	"stock_errors":         "Ошибки: %d",
+	"stock_line":           "строка %d",
+	"stock_error_quantity": "некорректное количество %q",
+	"stock_choose_apteka":  "В какую аптеку загрузить остатки?",
+	"stock_expired":        "Файл устарел, отправьте его еще раз",
+
+	"apteka_invite":       "Пригласите аптекарей филиала командой /invite",
+	"apteka_created":      "Аптека успешно создана! ✅",
+	"apteka_upload_stock": "Теперь отправьте мне файл с остатками лекарств, выгруженный из 1С",
+	"apteka_pending":      "Заявка отправлена администратору. После проверки аптека появится в поиске, а вы станете ее аптекарем ⏳",
+	"apteka_review":       "🆕 Новая аптека на проверку",
+	"apteka_approved":     "Аптека прошла проверку! ✅",
+	"apteka_rejected":     "Аптека не прошла проверку ❌",
+	"apteka_refused":      "❌ Отклонено",
+	"apteka_reviewed":     "Эта аптека уже проверена",
+	"no_access":           "У вас нет доступа к этой аптеке",
+	"no_aptekas":          "У вас пока нет аптек, создайте ее командой /create_apteka",
+	"invite_who":          "Кого Вы хотите пригласить?",
+	"invite_created":      "Отправьте эту ссылку сотруднику, она действует 7 дней и только один раз:",
+	"invite_invalid":      "Приглашение недействительно или уже использовано",
+	"joined_apteka":       "Вы добавлены аптекарем в аптеку ✅",
+	"joined_chain":        "Вы назначены управляющим сетью аптек ✅\n\nСоздавайте филиалы командой /create_apteka и приглашайте аптекарей командой /invite",
+	"create_chain_usage":  "Укажите название сети: /create_chain Название",
+
+	"ask_location":   "Отправьте геопозицию, и я покажу ближайшие аптеки",
+	"location_saved": "Геопозиция сохранена 📍 Теперь аптеки сортируются по расстоянию",
+
+	"open_24x7":       "Открыто круглосуточно",
+	"open_until":      "Открыто до %s",
+	"open":            "Открыто",
+	"closed":          "Закрыто",
+	"closed_opens_at": "Закрыто, откроется в %s",
+	"closed_opens_on": "Закрыто, откроется %s в %s",
+	"weekday_sun":     "Вс",
+	"weekday_mon":     "Пн",
+	"weekday_tue":     "Вт",
+	"weekday_wed":     "Ср",
+	"weekday_thu":     "Чт",
+	"weekday_fri":     "Пт",
+	"weekday_sat":     "Сб",
+	"inventory_never": "остатки еще не загружались",
+	"inventory_today": "остатки обновлены сегодня",
+	"inventory_days":  "остатки обновлены %d день назад|остатки обновлены %d дня назад|остатки обновлены %d дней назад",
+	"inventory_reminder": "⏰ Пора обновить остатки в аптеке «%s», %s: %s.\n\n" +
+		"Пока база не обновлена, аптека показывается в поиске ниже остальных. " +
+		"Отправьте мне файл с остатками, выгруженный из 1С",
+	"inventory_escalation": "⚠️ Филиал «%s», %s не обновляет остатки: %s, напоминаний без ответа: %d",
+
+	"webapp_error":      "Что-то пошло не так - попробуйте еще раз",
+	"validation_errors": "Проверьте данные в форме:",
+	"label_name":        "Название",
+	"label_city":        "Город",
+	"label_address":     "Адрес",
+	"label_phone":       "Телефон",
+	"label_room_count":  "Количество комнат",
+	"label_price":       "Цена",
+	"label_work_hours":  "Часы работы",
+	"label_location":    "Геопозиция",
+	"label_rooms_min":   "Комнат от",
+	"label_rooms_max":   "Комнат до",
+	"label_price_min":   "Цена от",
+	"label_price_max":   "Цена до",
+	"label_sort":        "Сортировка",
+	"label_page":        "Страница",
+	"label_limit":       "Объявлений на странице",
+
+	"error_required":           "обязательное поле",
+	"error_too_short":          "не короче %d символов",
+	"error_too_long":           "не длиннее %d символов",
+	"error_range":              "от %d до %d",
+	"error_format":             "неверный формат",
+	"error_type":               "неверный тип значения",
+	"error_invalid":            "неверное значение",
+	"error_phone_format":       "номер Кыргызстана, Казахстана или России, например 0555 123 456",
+	"error_work_hours_format":  "укажите в виде «Пн-Пт 9:00-21:00, Сб 10:00-18:00»",
+	"error_work_hours_invalid": "неверный часовой пояс или дата",
+	"error_location_invalid":   "неверные координаты",
+	"error_sort_format":        "одно из new, price, -price, rooms",
+
+	"photo_unsupported": "Не удалось открыть фото, отправьте изображение в формате JPEG, PNG или WebP",
+	"photo_too_big":     "Фото слишком большое, можно не больше %d МБ",
+	"too_many_photos":   "К объявлению можно приложить не больше %d фото",
+	"photo_as_file":     "Чтобы приложить фото к объявлению, отправьте его как фото, а не файлом",
+
+	"house_preview":        "Проверьте объявление перед публикацией:",
+	"house_edit":           "Исправьте данные в форме и отправьте ее снова, фото сохранены",
+	"house_cancelled":      "Объявление удалено",
+	"house_not_found":      "Объявление не найдено или уже опубликовано",
+	"house_unavailable":    "Объявление больше не доступно",
+	"houses_not_found":     "По Вашему запросу объявлений нет 😔\n\nПример поиска: /houses Бишкек 2-3 до 40000",
+	"house_search_expired": "Поиск устарел, повторите команду /houses",
+	"houses_page":          "Найдено %d объявление, страница %d из %d|Найдено %d объявления, страница %d из %d|Найдено %d объявлений, страница %d из %d",
+	"rooms":                "%d комната|%d комнаты|%d комнат",
+	"photos":               "%d фото|%d фото|%d фото",
+	"price":                "%s сом",
+	"price_negotiable":     "Цена договорная",
+	"bounds_from":          "от %d",
+	"bounds_to":            "до %d",
+
+	"no_listings":             "У Вас пока нет объявлений",
+	"status_pending":          "⏳ На модерации",
+	"status_rejected":         "❌ Отклонено: %s",
+	"status_published":        "✅ Опубликовано",
+	"status_unpublished":      "⏸ Снято с публикации",
+	"status_draft":            "📝 Черновик",
+	"listing_edit":            "Нажмите кнопку ниже, исправьте данные и отправьте форму",
+	"listing_updated":         "Объявление обновлено ✅",
+	"listing_send_photos":     "Отправьте новые фото, они заменят текущие. Когда закончите, нажмите «Готово»",
+	"listing_no_photos":       "Вы еще не отправили ни одного фото",
+	"listing_photos_replaced": "Фото обновлены ✅",
+
+	"verify_phone":      "Подтвердите номер телефона: объявления с подтвержденным номером проходят модерацию быстрее. Номер в объявлении должен совпадать с номером Вашего Telegram",
+	"phone_verified":    "Номер подтвержден ✅",
+	"contact_not_yours": "Отправьте свой контакт кнопкой «Подтвердить номер»",
+	"phone_unsupported": "Поддерживаются номера Кыргызстана, Казахстана и России",
+	"phone_mismatch":    "Номер %s сохранен, но он не совпадает с номером в Ваших объявлениях",
+
+	"house_submitted":            "Объявление отправлено на модерацию ⏳ Мы сообщим, когда его проверят",
+	"house_approved":             "Объявление прошло модерацию и опубликовано ✅",
+	"house_rejected":             "Объявление отклонено модератором ❌\nПричина: %s\n\nИсправьте его в /my_listings и отправьте снова",
+	"moderation_new":             "🆕 Объявление на модерацию",
+	"moderation_approved":        "✅ Одобрено",
+	"moderation_rejected":        "❌ Отклонено: %s",
+	"moderation_done":            "Это объявление уже проверено",
+	"moderation_ask_reason":      "Напишите причину отказа ответом на это сообщение",
+	"moderation_reason_too_long": "Причина слишком длинная, не больше %d символов",
+	"reject_phone":               "Неверный номер телефона",
+	"reject_duplicate":           "Дубликат объявления",
+	"reject_content":             "Недопустимое содержание",
+	"reject_photos":              "Неподходящие фото",
+	"reject_address":             "Неполный или неверный адрес",
+	"flag_banned_word":           "Запрещенные слова",
+	"flag_unverified_phone":      "Номер телефона не подтвержден владельцем",
+	"flag_duplicate_address":     "Такой адрес уже есть в других объявлениях",
+	"flag_duplicate_phone":       "Телефон указан в объявлениях другого владельца",
+
+	"alert_saved":     "Готово 🔔 Сообщу, когда появится подходящее объявление. Подписки: /alerts",
+	"alert_deleted":   "Подписка удалена",
+	"too_many_alerts": "У Вас уже %d подписок, удалите лишние командой /alerts",
+	"no_alerts":       "У Вас нет подписок. Найдите жилье командой /houses и нажмите «Сообщать о новых»",
+	"alerts":          "Ваши подписки, нажмите чтобы отписаться:",
+	"all_houses":      "Все объявления",
+	"new_house":       "🔔 Новое объявление по Вашей подписке",
+
+	"language_choose":  "Выберите язык:",
+	"language_changed": "Язык изменен ✅",
+
+	"session_not_allowed":  "Вы не можете запустить сессию. Возможно, у вас нет подписки.",
+	"session_topic":        "Тема %d",
+	"session_choose_topic": "Выберите тему:",
+	"session_rate":         "Оцените сессию от 1 до 5:",
+	"session_topic_first":  "Сначала выберите тему, затем можете писать сообщения.",
+	"session_topic_needed": "Сначала выберите тему.",
+	"session_last_closed":  "Последняя сессия закрыта. Вы можете начать новый диалог.",
+	"session_closed":       "Сессия закрыта. Вы можете начать новый диалог.",
+	"session_limit":        "Лимит сообщений исчерпан. Вы можете начать новый диалог.",
+	"session_none":         "У вас нет активной сессии.",
+	"session_finished":     "Сессия завершена. Вы можете начать новый диалог.",
+	"session_trial":        "Пробный период активирован! У вас 2 сессии.",
+	"session_expired":      "Ваша подписка истекла. Приобретите новую",
+	"session_unlimited":    "У вас неограниченные сессии.",
+	"session_no_left":      "У вас не осталось сессий. Купите новые",
+	"session_allowed":      "Вы можете начать сессию.",
+	"server_error":         "Ошибка сервера, попробуйте позже.",
+	"support_prompt":       "Отправьте ваше сообщение в поддержку:",
+	"support_sent":         "Ваше сообщение отправлено в поддержку.",
+	"feedback_prompt":      "Отправьте обратную связь на бота:",
+	"feedback_thanks":      "Спасибо, ваш отзыв очень важен.",
+
+	"user_not_found":       "Ошибка: пользователь не найден.",
+	"plan_premium_active":  "У вас уже активен тариф 'Premium'. Он истекает: %s.\nВы сможете продлить подписку после окончания.",
+	"plan_standard_active": "У вас активен тариф 'Standard'. Вы можете перейти на 'Premium'.",
+	"plan_basic_active":    "У вас активен тариф 'Basic'. Вы можете перейти на 'Standard' или 'Premium'.",
+	"plan_trial_expired":   "Ваш пробный период истёк. Выберите тарифный план:",
+	"plan_trial_active":    "Вы используете пробный тариф. Он истекает: %s.",
+	"plan_none":            "У вас нет активной подписки. Выберите тарифный план:",
+	"plan_buy":             "Купить %s",
+	"plan_basic":           "Basic (30 дней, 30 сессий)",
+	"plan_standard":        "Standard (60 дней, 60 сессий)",
+	"plan_premium":         "Premium (90 дней, безлимит)",
+	"subscription_invalid": "Некорректный выбор подписки.",
+	"subscription_error":   "Ошибка при оформлении подписки.",
+	"subscription_done":    "Вы успешно оформили подписку: %s",
+
+	"btn_verify_phone":   "📞 Подтвердить номер",
+	"btn_send_location":  "📍 Отправить геопозицию",
+	"btn_create_apteka":  "Создать аптеку",
+	"btn_invite_manager": "Управляющего сетью",
+	"btn_show":           "Посмотреть",
+	"btn_open_2gis":      "Открыть в 2ГИС",
+	"btn_publish":        "Опубликовать",
+	"btn_edit":           "Изменить",
+	"btn_cancel":         "Отменить",
+	"btn_photos":         "Фото",
+	"btn_notify_new":     "🔔 Сообщать о новых",
+	"btn_unsubscribe":    "Отписаться",
+	"btn_unpublish":      "Снять с публикации",
+	"btn_submit":         "Отправить на модерацию",
+	"btn_replace_photos": "Заменить фото",
+	"btn_delete":         "Удалить",
+	"btn_delete_confirm": "Да, удалить",
+	"btn_no":             "Нет",
+	"btn_done":           "Готово",
+	"btn_edit_listing":   "Изменить объявление",
+	"btn_approve":        "✅ Одобрить",
+	"btn_reject":         "❌ Отклонить",
+	"btn_reject_other":   "✍️ Другая причина",
+	"btn_back":           "◀️ Назад",
+
+	"cmd_create_apteka": "Создать аптеку",
+	"cmd_location":      "Указать геопозицию",
+	"cmd_my_aptekas":    "Мои аптеки",
+	"cmd_invite":        "Пригласить аптекаря",
+	"cmd_houses":        "Найти жилье",
+	"cmd_my_listings":   "Мои объявления",
+	"cmd_alerts":        "Подписки на объявления",
+	"cmd_language":      "Язык",
+}
@@ -0,0 +1,220 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"os/exec"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+var ErrUnsupported = errors.New("unsupported image format")
+
+// bigger images are rejected before decoding to not run out of memory
+const maxPixels = 50_000_000
+
+type Format string
+
+const (
+	JPEG Format = "jpeg"
+	WebP Format = "webp"
+)
+
+// Size is a variant of a photo fitting into a Max x Max square
+type Size struct {
+	Name string
+	Max  int
+}
+
+var (
+	Original  = Size{Name: "original", Max: 2048}
+	Medium    = Size{Name: "medium", Max: 1024}
+	Thumbnail = Size{Name: "thumbnail", Max: 320}
+)
+
+// Sizes are ordered from the largest, every next size is scaled from the previous one
+var Sizes = []Size{Original, Medium, Thumbnail}
+
+type Variant struct {
+	Size   Size
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Processor validates uploaded images, applies the EXIF orientation and
+// re-encodes them in several sizes, re-encoding drops all metadata including GPS
+type Processor struct {
+	format  Format
+	quality int
+	ffmpeg  string
+}
+
+// NewProcessor creates a processor encoding to the format, WebP is encoded
+// with ffmpeg and falls back to JPEG when ffmpeg is not installed
+func NewProcessor(format Format) *Processor {
+	p := &Processor{format: JPEG, quality: 82}
+	if format == WebP {
+		p.format = WebP
+		ffmpeg, err := exec.LookPath("ffmpeg")
+		if err != nil {
+			log.Printf("ffmpeg is not found, photos are encoded as JPEG")
+			p.format = JPEG
+		}
+		p.ffmpeg = ffmpeg
+	}
+	return p
+}
+
+// Detect returns the type of the image by its magic bytes
+func Detect(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8, 0xff}):
+		return "jpeg", nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png", nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif", nil
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp", nil
+	}
+	return "", ErrUnsupported
+}
+
+func (p *Processor) Process(data []byte) ([]Variant, error) {
+	kind, err := Detect(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := decodeConfig(kind, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, err.Error())
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("%w: image is too large", ErrUnsupported)
+	}
+	img, err := decode(kind, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, err.Error())
+	}
+
+	// orientation is applied after the first downscale, it is cheaper on a smaller image
+	current := fit(img, Sizes[0].Max)
+	if kind == "jpeg" {
+		current = orient(current, jpegOrientation(data))
+	}
+
+	variants := make([]Variant, 0, len(Sizes))
+	for i, size := range Sizes {
+		if i > 0 {
+			current = fit(current, size.Max)
+		}
+		encoded, err := p.encode(current)
+		if err != nil {
+			return nil, err
+		}
+		bounds := current.Bounds()
+		variants = append(variants, Variant{Size: size, Data: encoded, Width: bounds.Dx(), Height: bounds.Dy()})
+	}
+	return variants, nil
+}
+
+func decode(kind string, data []byte) (image.Image, error) {
+	r := bytes.NewReader(data)
+	switch kind {
+	case "jpeg":
+		return jpeg.Decode(r)
+	case "png":
+		return png.Decode(r)
+	case "gif":
+		return gif.Decode(r)
+	default:
+		return webp.Decode(r)
+	}
+}
+
+func decodeConfig(kind string, data []byte) (image.Config, error) {
+	r := bytes.NewReader(data)
+	switch kind {
+	case "jpeg":
+		return jpeg.DecodeConfig(r)
+	case "png":
+		return png.DecodeConfig(r)
+	case "gif":
+		return gif.DecodeConfig(r)
+	default:
+		return webp.DecodeConfig(r)
+	}
+}
+
+// fit scales the image down into a max x max square on a white background,
+// transparent pixels would turn black in JPEG otherwise
+func fit(img image.Image, max int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > max || h > max {
+		if w >= h {
+			w, h = max, h*max/w
+		} else {
+			w, h = w*max/h, max
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max1(w), max1(h)))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	if w == bounds.Dx() && h == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	}
+	return dst
+}
+
+func max1(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func (p *Processor) encode(img image.Image) ([]byte, error) {
+	if p.format == WebP {
+		return p.encodeWebP(img)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeWebP pipes the image to ffmpeg as png, the standard library has no WebP encoder
+func (p *Processor) encodeWebP(img image.Image) ([]byte, error) {
+	var input, output, stderr bytes.Buffer
+	if err := (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(&input, img); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(p.ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-f", "png_pipe", "-i", "pipe:0",
+		"-c:v", "libwebp", "-quality", fmt.Sprint(p.quality),
+		"-f", "webp", "pipe:1",
+	)
+	cmd.Stdin = &input
+	cmd.Stdout = &output
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w: %s", err, stderr.String())
+	}
+	return output.Bytes(), nil
+}
@@ -0,0 +1,175 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader is a PNG with only the header, enough for DecodeConfig
+func pngHeader(w, h int) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(w))
+	binary.BigEndian.PutUint32(ihdr[8:], uint32(h))
+	ihdr[12], ihdr[13] = 8, 6 // 8 bit RGBA
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestDetect(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, testImage(2, 2), nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"jpeg", encodeJPEG(t, testImage(2, 2)), "jpeg"},
+		{"png", encodePNG(t, testImage(2, 2)), "png"},
+		{"gif", gifData.Bytes(), "gif"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "webp"},
+		{"riff wave", []byte("RIFF\x00\x00\x00\x00WAVEfmt "), ""},
+		{"pdf", []byte("%PDF-1.7"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		got, err := Detect(tt.data)
+		if got != tt.want || (tt.want == "") != errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: Detect() = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		w, h, max int
+		wantW     int
+		wantH     int
+	}{
+		{4000, 1000, 2048, 2048, 512},
+		{1000, 4000, 1024, 256, 1024},
+		{300, 200, 320, 300, 200},
+		{5000, 1, 320, 320, 1},
+	}
+	for _, tt := range tests {
+		got := fit(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), tt.max).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("fit(%dx%d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.max, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+
+	// transparent pixels are put on white, they would turn black in JPEG
+	transparent := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	if c := fit(transparent, 10).RGBAAt(1, 1); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("transparent pixel = %v, want white", c)
+	}
+
+	// an image with the origin not at 0,0 is copied from its bounds
+	sub := testImage(10, 10).SubImage(image.Rect(5, 5, 10, 10))
+	if c := fit(sub, 10).RGBAAt(0, 0); c.R != 5 || c.G != 5 {
+		t.Errorf("sub image pixel = %v, want the one at 5,5", c)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	p := NewProcessor(JPEG)
+
+	variants, err := p.Process(encodePNG(t, testImage(3000, 1500)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		size Size
+		w, h int
+	}{{Original, 2048, 1024}, {Medium, 1024, 512}, {Thumbnail, 320, 160}}
+	if len(variants) != len(want) {
+		t.Fatalf("%d variants, want %d", len(variants), len(want))
+	}
+	for i, v := range variants {
+		if v.Size != want[i].size || v.Width != want[i].w || v.Height != want[i].h {
+			t.Errorf("variant %d = %s %dx%d, want %s %dx%d", i, v.Size.Name, v.Width, v.Height, want[i].size.Name, want[i].w, want[i].h)
+		}
+		if kind, _ := Detect(v.Data); kind != "jpeg" {
+			t.Errorf("variant %s is encoded as %q", v.Size.Name, kind)
+		}
+		config, err := jpeg.DecodeConfig(bytes.NewReader(v.Data))
+		if err != nil || config.Width != v.Width || config.Height != v.Height {
+			t.Errorf("variant %s decodes to %dx%d, %v", v.Size.Name, config.Width, config.Height, err)
+		}
+	}
+}
+
+func TestProcessStripsMetadata(t *testing.T) {
+	data := withEXIF(encodeJPEG(t, testImage(300, 100)), exifTIFF(binary.LittleEndian, 6, "GPS 42.87 74.59"))
+
+	variants, err := NewProcessor(JPEG).Process(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range variants {
+		if bytes.Contains(v.Data, []byte("Exif")) || bytes.Contains(v.Data, []byte("GPS 42.87")) {
+			t.Errorf("variant %s keeps the EXIF", v.Size.Name)
+		}
+		if jpegOrientation(v.Data) != 1 {
+			t.Errorf("variant %s keeps the orientation", v.Size.Name)
+		}
+	}
+	// rotated 90 clockwise the landscape photo becomes a portrait one
+	if v := variants[0]; v.Width != 100 || v.Height != 300 {
+		t.Errorf("original is %dx%d, want 100x300", v.Width, v.Height)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := map[string][]byte{
+		"not an image": []byte("<html>Not Found</html>"),
+		"broken jpeg":  append([]byte{0xff, 0xd8, 0xff, 0xe0}, make([]byte, 20)...),
+		"too large":    pngHeader(10000, 10000),
+	}
+	for name, data := range tests {
+		if _, err := NewProcessor(JPEG).Process(data); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: Process() error = %v, want ErrUnsupported", name, err)
+		}
+	}
+}
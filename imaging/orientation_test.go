@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// exifTIFF builds a TIFF structure with the orientation tag and a text tag
+// standing for the other metadata like GPS
+func exifTIFF(order binary.AppendByteOrder, orientation int, text string) []byte {
+	tiff := []byte("II")
+	if order == binary.BigEndian {
+		tiff = []byte("MM")
+	}
+	tiff = order.AppendUint16(tiff, 42)
+	tiff = order.AppendUint32(tiff, 8)
+
+	textOffset := 8 + 2 + 2*12 + 4
+	tiff = order.AppendUint16(tiff, 2)
+	// ImageDescription, ASCII
+	tiff = order.AppendUint16(tiff, 0x010e)
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = order.AppendUint32(tiff, uint32(len(text)+1))
+	tiff = order.AppendUint32(tiff, uint32(textOffset))
+	// Orientation, SHORT
+	tiff = order.AppendUint16(tiff, exifOrientationTag)
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, uint16(orientation))
+	tiff = order.AppendUint16(tiff, 0)
+	// no next IFD
+	tiff = order.AppendUint32(tiff, 0)
+	return append(append(tiff, text...), 0)
+}
+
+// withEXIF inserts the APP1 segment right after the start of the JPEG
+func withEXIF(jpegData, tiff []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := append([]byte{}, jpegData[:2]...)
+	data = append(data, segment...)
+	return append(data, jpegData[2:]...)
+}
+
+func TestJpegOrientation(t *testing.T) {
+	plain := []byte{0xff, 0xd8, 0xff, 0xda, 0x00, 0x02}
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", withEXIF(plain, exifTIFF(binary.LittleEndian, 6, "x")), 6},
+		{"big endian", withEXIF(plain, exifTIFF(binary.BigEndian, 8, "x")), 8},
+		{"after padding", withEXIF([]byte{0xff, 0xd8, 0xff, 0xff, 0xda}, exifTIFF(binary.LittleEndian, 3, "")), 3},
+		{"no exif", plain, 1},
+		{"out of range", withEXIF(plain, exifTIFF(binary.LittleEndian, 9, "x")), 1},
+		{"unknown byte order", withEXIF(plain, append([]byte("XX"), exifTIFF(binary.LittleEndian, 6, "x")[2:]...)), 1},
+		{"truncated tiff", withEXIF(plain, exifTIFF(binary.LittleEndian, 6, "x")[:12]), 1},
+		{"segment past the end", []byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff, 'E', 'x'}, 1},
+		{"garbage", []byte{0xff, 0xd8, 0x00, 0x00, 0x00, 0x00}, 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: jpegOrientation() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// a b c
+	// d e f
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i, name := range "abcdef" {
+		src.SetRGBA(i%3, i/3, color.RGBA{R: uint8(name), A: 255})
+	}
+
+	tests := []struct {
+		orientation int
+		want        []string
+	}{
+		{1, []string{"abc", "def"}},
+		{2, []string{"cba", "fed"}},
+		{3, []string{"fed", "cba"}},
+		{4, []string{"def", "abc"}},
+		{5, []string{"ad", "be", "cf"}},
+		{6, []string{"da", "eb", "fc"}},
+		{7, []string{"fc", "eb", "da"}},
+		{8, []string{"cf", "be", "ad"}},
+		{0, []string{"abc", "def"}},
+	}
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+		var got []string
+		for y := 0; y < dst.Bounds().Dy(); y++ {
+			row := ""
+			for x := 0; x < dst.Bounds().Dx(); x++ {
+				row += string(rune(dst.RGBAAt(x, y).R))
+			}
+			got = append(got, row)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("orient(%d) = %q, want %q", tt.orientation, got, tt.want)
+		}
+	}
+}
@@ -9,14 +9,19 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	tg "github.com/PaulSonOfLars/gotgbot/v2"
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/jub0bs/fcors"
+	"github.com/oybek/jethouse/blob"
 	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/imaging"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/phone"
 	"github.com/oybek/jethouse/telegram"
 )
 
@@ -24,6 +29,23 @@ type Config struct {
 	mdb           db.Config
 	tgbotApiToken string
 	openAiToken   string
+	// how long uploaded stock is considered fresh
+	inventoryFreshness time.Duration
+	voiceReplies       bool
+	// phoneCountry is assumed for numbers without the country code
+	phoneCountry string
+	// chat where new house listings are moderated
+	moderatorsChatId int64
+	// houseWebAppUrl is the web app editing the listings
+	houseWebAppUrl string
+	// blobStore is "local" or "s3"
+	blobStore string
+	photosDir string
+	// photoFormat is "jpeg" or "webp"
+	photoFormat imaging.Format
+	s3          blob.S3Config
+	// updates are received by long polling or by webhook
+	updates telegram.UpdatesConfig
 }
 
 func main() {
@@ -33,7 +55,40 @@ func main() {
 		mdb:           db.Config{Url: os.Getenv("ME_CONFIG_MONGODB_URL")},
 		tgbotApiToken: os.Getenv("TG_BOT_API_TOKEN"),
 		openAiToken:   os.Getenv("OPEN_AI_TOKEN"),
+
+		inventoryFreshness: durationEnv("INVENTORY_FRESHNESS", 72*time.Hour),
+		voiceReplies:       os.Getenv("VOICE_REPLIES") == "true",
+		moderatorsChatId:   int64Env("MODERATORS_CHAT_ID"),
+		phoneCountry:       os.Getenv("PHONE_COUNTRY"),
+		houseWebAppUrl:     os.Getenv("HOUSE_WEBAPP_URL"),
+
+		blobStore:   os.Getenv("BLOB_STORE"),
+		photosDir:   os.Getenv("PHOTOS_DIR"),
+		photoFormat: imaging.Format(os.Getenv("PHOTO_FORMAT")),
+		s3: blob.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		},
+		updates: telegram.UpdatesConfig{
+			Mode:               os.Getenv("TG_UPDATES"),
+			WebhookURL:         os.Getenv("TG_WEBHOOK_URL"),
+			WebhookSecret:      os.Getenv("TG_WEBHOOK_SECRET"),
+			DropPendingUpdates: os.Getenv("TG_DROP_PENDING_UPDATES") == "true",
+		},
+	}
+
+	if err := cfg.updates.Validate(); err != nil {
+		log.Fatalf("Invalid updates config: %v", err)
+	}
+
+	country, err := phone.ParseCountry(cfg.phoneCountry)
+	if err != nil {
+		log.Fatalf("Invalid PHONE_COUNTRY: %v", err)
 	}
+	phone.DefaultCountry = country
 
 	mongoClient, err := db.Create(cfg.mdb)
 	if err != nil {
@@ -41,6 +96,13 @@ func main() {
 	}
 	defer mongoClient.Disconnect(context.Background())
 
+	if err := db.CreateIndexes(context.Background(), mongoClient); err != nil {
+		log.Fatalf("Could not create indexes: %v", err)
+	}
+	if err := db.Migrate(context.Background(), mongoClient); err != nil {
+		log.Fatalf("Could not migrate database: %v", err)
+	}
+
 	//
 	botOpts := tg.BotOpts{
 		BotClient: &tg.BaseBotClient{
@@ -59,12 +121,28 @@ func main() {
 	openaiClient := openai.NewClient(cfg.openAiToken)
 
 	photoCache := ttlcache.New(
-		ttlcache.WithTTL[int64, []uuid.UUID](10*time.Minute),
-		ttlcache.WithDisableTouchOnHit[int64, []uuid.UUID](),
+		ttlcache.WithTTL[int64, []model.Photo](10*time.Minute),
+		ttlcache.WithDisableTouchOnHit[int64, []model.Photo](),
 	)
 
-	longPoll := telegram.NewLongPoll(bot, mongoClient, openaiClient, photoCache)
-	go longPoll.Run()
+	blobStore, err := createBlobStore(cfg)
+	if err != nil {
+		log.Fatalf("Could not set up blob store: %v", err)
+	}
+
+	longPoll := telegram.NewLongPoll(telegram.Config{
+		Bot:                bot,
+		MongoClient:        mongoClient,
+		OpenAIClient:       openaiClient,
+		PhotoCache:         photoCache,
+		BlobStore:          blobStore,
+		ImageProcessor:     imaging.NewProcessor(cfg.photoFormat),
+		InventoryFreshness: cfg.inventoryFreshness,
+		VoiceReplies:       cfg.voiceReplies,
+		ModeratorsChatId:   cfg.moderatorsChatId,
+		HouseWebAppUrl:     cfg.houseWebAppUrl,
+		Updates:            cfg.updates,
+	})
 
 	cors, _ := fcors.AllowAccess(
 		fcors.FromAnyOrigin(),
@@ -78,13 +156,66 @@ func main() {
 	)
 
 	r := mux.NewRouter()
-	r.HandleFunc("/request/{uuid}", http.HandlerFunc(longPoll.GetRequest))
+	r.HandleFunc("/apteka/search/{id}", longPoll.GetRequest).Methods(http.MethodGet)
+	r.HandleFunc("/webapp/validate", longPoll.ValidateWebApp).Methods(http.MethodPost)
+	r.HandleFunc("/houses", longPoll.SearchHouses).Methods(http.MethodGet)
+	r.HandleFunc("/houses/{id}", longPoll.GetHouse).Methods(http.MethodGet)
+	r.HandleFunc("/photos/{key}", longPoll.GetPhoto).Methods(http.MethodGet, http.MethodHead)
+	if cfg.updates.Mode == telegram.UpdatesWebhook {
+		r.HandleFunc(telegram.WebhookPath, longPoll.WebhookHandler()).Methods(http.MethodPost)
+	}
 	http.Handle("/", cors(r))
 	go http.ListenAndServe(":5556", nil)
 
+	// the server is up before the webhook is set
+	go longPoll.Run()
+
 	// listen for ctrl+c signal from terminal
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
 	log.Println(fmt.Sprint(<-ch))
 	log.Println("Stopping the bot...")
+	if err := longPoll.Stop(); err != nil {
+		log.Printf("Could not stop the bot: %v", err)
+	}
+}
+
+// durationEnv reads a duration like "72h" from the environment variable
+func durationEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid %s=%q: expected a positive duration like 72h", name, value)
+	}
+	return d
+}
+
+// int64Env reads an optional integer like a chat id, zero if it is not set
+func int64Env(name string) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s=%q: expected an integer", name, value)
+	}
+	return n
+}
+
+func createBlobStore(cfg Config) (blob.Store, error) {
+	switch cfg.blobStore {
+	case "s3":
+		return blob.NewS3(context.Background(), cfg.s3)
+	case "local", "":
+		dir := cfg.photosDir
+		if dir == "" {
+			dir = "photos"
+		}
+		return blob.NewLocal(dir)
+	}
+	return nil, fmt.Errorf("unknown blob store %q", cfg.blobStore)
 }
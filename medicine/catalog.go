@@ -0,0 +1,201 @@
+package medicine
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/oybek/jethouse/model"
+)
+
+// MinScore is the lowest similarity at which a name is still matched to the catalog
+const MinScore = 0.75
+
+// ConfidentScore is the similarity above which a match needs no confirmation
+const ConfidentScore = 0.9
+
+// SuggestScore is the lowest similarity offered to the user as "did you mean"
+const SuggestScore = 0.5
+
+// name priorities, a key shared by several medicines goes to the one
+// having it as a more specific name
+const (
+	priorityINN = iota
+	prioritySynonym
+	priorityBrand
+	priorityName
+)
+
+type catalogKey struct {
+	key      string
+	index    int
+	priority int
+}
+
+// Catalog resolves dictated or typed medicine names to catalog entries
+type Catalog struct {
+	medicines []model.Medicine
+	byID      map[primitive.ObjectID]int
+	byKey     map[string]catalogKey
+	byINN     map[string][]int
+	keys      []catalogKey
+	trigrams  map[string][]int
+}
+
+type Match struct {
+	Medicine *model.Medicine
+	Score    float64
+}
+
+func NewCatalog(medicines []model.Medicine) *Catalog {
+	c := &Catalog{
+		medicines: medicines,
+		byID:      map[primitive.ObjectID]int{},
+		byKey:     map[string]catalogKey{},
+		byINN:     map[string][]int{},
+		trigrams:  map[string][]int{},
+	}
+
+	for i, m := range medicines {
+		c.byID[m.ID] = i
+		if m.INN != "" {
+			inn := Key(m.INN)
+			c.byINN[inn] = append(c.byINN[inn], i)
+			c.add(m.INN, i, priorityINN)
+		}
+		c.add(m.Name, i, priorityName)
+		for _, brand := range m.Brands {
+			c.add(brand, i, priorityBrand)
+		}
+		for _, synonym := range m.Synonyms {
+			c.add(synonym, i, prioritySynonym)
+		}
+	}
+
+	for _, k := range c.byKey {
+		c.keys = append(c.keys, k)
+		for _, trigram := range trigrams(k.key) {
+			c.trigrams[trigram] = append(c.trigrams[trigram], len(c.keys)-1)
+		}
+	}
+	return c
+}
+
+func (c *Catalog) add(name string, index, priority int) {
+	key := Key(name)
+	if key == "" {
+		return
+	}
+	if existing, ok := c.byKey[key]; ok && existing.priority >= priority {
+		return
+	}
+	c.byKey[key] = catalogKey{key: key, index: index, priority: priority}
+}
+
+func (c *Catalog) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.medicines)
+}
+
+func (c *Catalog) Get(id primitive.ObjectID) *model.Medicine {
+	if c == nil {
+		return nil
+	}
+	if i, ok := c.byID[id]; ok {
+		return &c.medicines[i]
+	}
+	return nil
+}
+
+// Resolve finds the catalog entry for the name, first by exact key and
+// then by trigram candidates ranked with edit distance
+func (c *Catalog) Resolve(name string) (Match, bool) {
+	if c == nil {
+		return Match{}, false
+	}
+
+	key := Key(name)
+	if key == "" {
+		return Match{}, false
+	}
+	if k, ok := c.byKey[key]; ok {
+		return Match{Medicine: &c.medicines[k.index], Score: 1}, true
+	}
+
+	best := c.suggest(key, 1, MinScore)
+	if len(best) == 0 {
+		return Match{}, false
+	}
+	return best[0], true
+}
+
+// Suggest returns up to limit closest catalog entries for the name
+func (c *Catalog) Suggest(name string, limit int) []Match {
+	if c == nil {
+		return nil
+	}
+	return c.suggest(Key(name), limit, SuggestScore)
+}
+
+func (c *Catalog) suggest(key string, limit int, minScore float64) []Match {
+	if key == "" {
+		return nil
+	}
+
+	shared := map[int]int{}
+	for _, trigram := range trigrams(key) {
+		for _, i := range c.trigrams[trigram] {
+			shared[i]++
+		}
+	}
+
+	scores := map[int]float64{}
+	for i := range shared {
+		k := c.keys[i]
+		score := similarity(key, k.key)
+		if score < minScore {
+			continue
+		}
+		if score > scores[k.index] {
+			scores[k.index] = score
+		}
+	}
+
+	matches := make([]Match, 0, len(scores))
+	for index, score := range scores {
+		matches = append(matches, Match{Medicine: &c.medicines[index], Score: score})
+	}
+	sortMatches(matches)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Generics returns other medicines with the same active substance
+func (c *Catalog) Generics(id primitive.ObjectID) []model.Medicine {
+	m := c.Get(id)
+	if m == nil || m.INN == "" {
+		return nil
+	}
+
+	var generics []model.Medicine
+	for _, i := range c.byINN[Key(m.INN)] {
+		if c.medicines[i].ID != id {
+			generics = append(generics, c.medicines[i])
+		}
+	}
+	return generics
+}
+
+// Names returns canonical names of all catalog medicines
+func (c *Catalog) Names() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.medicines))
+	for _, m := range c.medicines {
+		names = append(names, m.Name)
+	}
+	return names
+}
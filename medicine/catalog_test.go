@@ -0,0 +1,130 @@
+package medicine
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/oybek/jethouse/model"
+)
+
+func testCatalog() *Catalog {
+	return NewCatalog([]model.Medicine{
+		{ID: primitive.NewObjectID(), Name: "Парацетамол", INN: "Paracetamol"},
+		{ID: primitive.NewObjectID(), Name: "Панадол", INN: "Paracetamol", Brands: []string{"Panadol"}},
+		{ID: primitive.NewObjectID(), Name: "Нурофен", INN: "Ibuprofen"},
+		{ID: primitive.NewObjectID(), Name: "Ибупрофен", INN: "Ibuprofen"},
+		{ID: primitive.NewObjectID(), Name: "Но-шпа", INN: "Drotaverine", Synonyms: []string{"дротаверин"}},
+	})
+}
+
+func TestResolve(t *testing.T) {
+	c := testCatalog()
+	tests := []struct {
+		name  string
+		want  string
+		exact bool
+	}{
+		{"Paracetamol", "Парацетамол", true},
+		{"панадол", "Панадол", true},
+		// the key of a name beats the same key of an active substance
+		{"ibuprofen", "Ибупрофен", true},
+		{"дротаверин", "Но-шпа", true},
+		{"ношпа", "Но-шпа", true},
+		{"парацитамол", "Парацетамол", false},
+		{"нурафен", "Нурофен", false},
+	}
+	for _, tt := range tests {
+		match, ok := c.Resolve(tt.name)
+		if !ok || match.Medicine.Name != tt.want {
+			t.Errorf("Resolve(%q) = %+v, %v, want %s", tt.name, match, ok, tt.want)
+			continue
+		}
+		if exact := match.Score == 1; exact != tt.exact {
+			t.Errorf("Resolve(%q) score = %f, exact %v", tt.name, match.Score, tt.exact)
+		}
+		if match.Score < MinScore {
+			t.Errorf("Resolve(%q) score %f is below MinScore", tt.name, match.Score)
+		}
+	}
+
+	for _, name := range []string{"абракадабра", "", "!!!"} {
+		if match, ok := c.Resolve(name); ok {
+			t.Errorf("Resolve(%q) = %s, want no match", name, match.Medicine.Name)
+		}
+	}
+
+	var empty *Catalog
+	if _, ok := empty.Resolve("нурофен"); ok {
+		t.Errorf("nil catalog resolved a name")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	c := testCatalog()
+	matches := c.Suggest("нурафен", 2)
+	if len(matches) == 0 || matches[0].Medicine.Name != "Нурофен" {
+		t.Fatalf("Suggest(нурафен) = %+v", matches)
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Errorf("suggestions are not sorted by score: %+v", matches)
+		}
+	}
+	if matches := c.Suggest("парацетамол", 1); len(matches) != 1 {
+		t.Errorf("Suggest limit 1 returned %d matches", len(matches))
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	c := testCatalog()
+	paracetamol, _ := c.Resolve("Парацетамол")
+	generics := c.Generics(paracetamol.Medicine.ID)
+	if len(generics) != 1 || generics[0].Name != "Панадол" {
+		t.Errorf("Generics(Парацетамол) = %+v, want Панадол", generics)
+	}
+	if generics := c.Generics(primitive.NewObjectID()); generics != nil {
+		t.Errorf("Generics(unknown) = %+v", generics)
+	}
+}
+
+func TestScan(t *testing.T) {
+	c := testCatalog()
+	got := c.Scan("мне нужен нурофен 200 мг 2 упаковки и парацетамол таблетки, и снова нурофен")
+	if len(got) != 2 {
+		t.Fatalf("Scan() = %+v, want 2 medicines", got)
+	}
+
+	nurofen := got[0]
+	if nurofen.Name != "Нурофен" || nurofen.Dosage != "200 мг" || nurofen.Quantity != 2 || !nurofen.Resolved() {
+		t.Errorf("Scan() first = %+v", nurofen)
+	}
+	paracetamol := got[1]
+	if paracetamol.Name != "Парацетамол" || paracetamol.Form != "таблетки" || paracetamol.Dosage != "" {
+		t.Errorf("Scan() second = %+v", paracetamol)
+	}
+
+	if got := c.Scan("500мг сироп"); len(got) != 0 {
+		t.Errorf("Scan(no names) = %+v", got)
+	}
+}
+
+func TestParseDetails(t *testing.T) {
+	tests := []struct {
+		tokens   []string
+		consumed int
+		want     model.RequestedMedicine
+	}{
+		{[]string{"500мг", "сироп", "еще"}, 2, model.RequestedMedicine{Dosage: "500 мг", Form: "сироп"}},
+		{[]string{"2,5", "мл"}, 2, model.RequestedMedicine{Dosage: "2,5 мл"}},
+		{[]string{"3", "штуки", "капли"}, 3, model.RequestedMedicine{Quantity: 3, Form: "капли"}},
+		{[]string{"3", "раза"}, 0, model.RequestedMedicine{}},
+	}
+	for _, tt := range tests {
+		var got model.RequestedMedicine
+		consumed := parseDetails(tt.tokens, &got)
+		if consumed != tt.consumed || got != tt.want {
+			t.Errorf("parseDetails(%q) = %d, %+v, want %d, %+v", tt.tokens, consumed, got, tt.consumed, tt.want)
+		}
+	}
+}
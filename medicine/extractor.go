@@ -0,0 +1,111 @@
+package medicine
+
+import (
+	"context"
+	"log"
+
+	"github.com/oybek/jethouse/model"
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+const extractPrompt = "Ты помощник аптеки. Пользователь надиктовал список лекарств, которые ему нужны. " +
+	"Выдели из текста каждое лекарство отдельно: название, дозировку, форму выпуска и количество упаковок. " +
+	"Не придумывай лекарства, которых нет в тексте. Исправляй очевидные ошибки распознавания речи в названиях."
+
+type extractedMedicine struct {
+	Name     string `json:"name" description:"Название лекарства в именительном падеже"`
+	Dosage   string `json:"dosage" description:"Дозировка, например 500 мг, или пустая строка"`
+	Form     string `json:"form" description:"Форма выпуска, например таблетки, сироп, или пустая строка"`
+	Quantity int    `json:"quantity" description:"Количество упаковок, 0 если не указано"`
+}
+
+type extractResponse struct {
+	Medicines []extractedMedicine `json:"medicines"`
+}
+
+// Extractor turns a free-form transcript into a list of requested medicines
+type Extractor struct {
+	openaiClient *openai.Client
+	schema       *jsonschema.Definition
+}
+
+func NewExtractor(openaiClient *openai.Client) *Extractor {
+	schema, err := jsonschema.GenerateSchemaForType(extractResponse{})
+	if err != nil {
+		panic("failed to generate medicine schema: " + err.Error())
+	}
+	return &Extractor{
+		openaiClient: openaiClient,
+		schema:       schema,
+	}
+}
+
+// Extract asks the LLM for a structured list and resolves every name in the
+// catalog, falling back to scanning the text with the catalog if the LLM
+// is unavailable or returns nothing
+func (e *Extractor) Extract(ctx context.Context, text string, catalog *Catalog) []model.RequestedMedicine {
+	extracted, err := e.extractLLM(ctx, text)
+	if err != nil {
+		log.Printf("[Extractor] LLM extraction failed, scanning catalog: %s", err.Error())
+	}
+	if len(extracted) == 0 {
+		return catalog.Scan(text)
+	}
+
+	medicines := make([]model.RequestedMedicine, 0, len(extracted))
+	for _, m := range extracted {
+		medicine := model.RequestedMedicine{
+			Name:     m.Name,
+			Dosage:   m.Dosage,
+			Form:     m.Form,
+			Quantity: m.Quantity,
+		}
+		if match, ok := catalog.Resolve(m.Name); ok {
+			medicine.MedicineID = match.Medicine.ID
+			medicine.Score = match.Score
+			if match.Score >= ConfidentScore {
+				medicine.Name = match.Medicine.Name
+			}
+		}
+		medicines = append(medicines, medicine)
+	}
+	return medicines
+}
+
+func (e *Extractor) extractLLM(ctx context.Context, text string) ([]extractedMedicine, error) {
+	resp, err := e.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: extractPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: text},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "medicines",
+				Schema: e.schema,
+				Strict: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, nil
+	}
+
+	var result extractResponse
+	if err := e.schema.Unmarshal(resp.Choices[0].Message.Content, &result); err != nil {
+		return nil, err
+	}
+
+	medicines := result.Medicines[:0]
+	for _, m := range result.Medicines {
+		if m.Name != "" {
+			medicines = append(medicines, m)
+		}
+	}
+	return medicines, nil
+}
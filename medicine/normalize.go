@@ -0,0 +1,123 @@
+package medicine
+
+import (
+	"strings"
+	"unicode"
+)
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "", 'ы': "i", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia",
+	// kyrgyz and kazakh letters
+	'ө': "o", 'ү': "u", 'ң': "n", 'ә': "a", 'ғ': "g", 'қ': "k", 'ұ': "u", 'һ': "h", 'і': "i",
+}
+
+// latinFolds brings latin spelling closer to the transliterated russian one,
+// so "Paracetamol" and "Парацетамол" get the same key. They are applied
+// to latin input only, cyrillic is transliterated after folding
+var latinFolds = strings.NewReplacer(
+	"ph", "f",
+	"th", "t",
+	"kh", "h",
+	"ch", "h",
+	"ck", "k",
+	"ce", "tse",
+	"ci", "tsi",
+	"cy", "tsi",
+	"c", "k",
+	"q", "k",
+	"w", "v",
+	"x", "ks",
+	"y", "i",
+	"j", "i",
+)
+
+// Key is the spelling-independent form of a medicine name: transliterated
+// to latin, phonetically folded, without spaces, punctuation and doubled letters
+func Key(name string) string {
+	var folded, latin strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			latin.WriteRune(r)
+			continue
+		case unicode.IsDigit(r):
+			folded.WriteString(latinFolds.Replace(latin.String()))
+			folded.WriteRune(r)
+		default:
+			folded.WriteString(latinFolds.Replace(latin.String()))
+			if s, ok := cyrillicToLatin[r]; ok {
+				folded.WriteString(s)
+			}
+		}
+		latin.Reset()
+	}
+	folded.WriteString(latinFolds.Replace(latin.String()))
+
+	// english "y" is usually heard as russian "ай": Tylol - Тайлол
+	vowels := strings.ReplaceAll(folded.String(), "ai", "i")
+
+	var key strings.Builder
+	var prev rune
+	for _, r := range vowels {
+		if r != prev {
+			key.WriteRune(r)
+		}
+		prev = r
+	}
+	return key.String()
+}
+
+// words splits the text into lowercase words keeping numbers like 2.5 intact
+func words(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%' && r != '.' && r != ','
+	})
+	result := fields[:0]
+	for _, field := range fields {
+		if field = strings.Trim(field, ".,"); field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+func trigrams(key string) []string {
+	padded := []rune("  " + key + " ")
+	result := make([]string, 0, len(padded)-2)
+	for i := 0; i+3 <= len(padded); i++ {
+		result = append(result, string(padded[i:i+3]))
+	}
+	return result
+}
+
+// similarity is 1 - levenshtein(a, b) / max(len(a), len(b))
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
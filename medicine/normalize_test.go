@@ -0,0 +1,75 @@
+package medicine
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"Парацетамол", "Paracetamol"},
+		{"Ибупрофен", "Ibuprofen"},
+		{"Тайлол", "Tylol"},
+		{"Но-шпа", "ношпа"},
+		{"Аспирин", "АСПИРИНН"},
+		{"Цитрамон", "Citramon"},
+		{"Фенибут", "Phenibut"},
+		{"Ксилен", "Xylen"},
+		{"Өтүк", "отук"},
+	}
+	for _, tt := range tests {
+		if Key(tt.a) != Key(tt.b) {
+			t.Errorf("Key(%q) = %q, Key(%q) = %q, want equal", tt.a, Key(tt.a), tt.b, Key(tt.b))
+		}
+	}
+
+	if got := Key("Парацетамол"); got != "paratsetamol" {
+		t.Errorf("Key(Парацетамол) = %q", got)
+	}
+	if got := Key(" - , "); got != "" {
+		t.Errorf("Key(punctuation) = %q, want empty", got)
+	}
+	if Key("Анальгин") == Key("Аспирин") {
+		t.Errorf("different names got the same key %q", Key("Анальгин"))
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := words("Мне нужен Нурофен, 200 мг и 2.5%! Ёж")
+	want := []string{"мне", "нужен", "нурофен", "200", "мг", "и", "2.5%", "еж"}
+	if !slices.Equal(got, want) {
+		t.Errorf("words() = %q, want %q", got, want)
+	}
+	if got := words(" ... "); len(got) != 0 {
+		t.Errorf("words(dots) = %q, want none", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"нурофен", "нурафен", 1 - 1.0/7},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	got := trigrams("abc")
+	want := []string{"  a", " ab", "abc", "bc "}
+	if !slices.Equal(got, want) {
+		t.Errorf("trigrams(abc) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package medicine
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/oybek/jethouse/model"
+)
+
+// longest catalog name, in words, looked up while scanning a transcript
+const maxNameWords = 3
+
+var (
+	numberPattern   = regexp.MustCompile(`^\d+(?:[.,]\d+)?$`)
+	dosagePattern   = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*(мг|mg|мкг|г|мл|ml|%)$`)
+	dosageUnits     = map[string]bool{"мг": true, "mg": true, "мкг": true, "г": true, "мл": true, "ml": true, "%": true}
+	quantityUnits   = []string{"шт", "штук", "штуки", "упаков", "пачк"}
+	formWords       = []string{"таблетки", "таблетка", "капсулы", "сироп", "мазь", "гель", "капли", "спрей", "порошок", "раствор", "суспензия", "свечи"}
+	scanIgnoreWords = map[string]bool{"и": true, "мне": true, "нужен": true, "нужна": true, "нужно": true, "нужны": true}
+)
+
+// Scan finds catalog medicines mentioned in the text together with the
+// dosage, form and quantity dictated right after each of them
+func (c *Catalog) Scan(text string) []model.RequestedMedicine {
+	if c == nil {
+		return nil
+	}
+
+	tokens := words(text)
+	seen := map[int]bool{}
+	var medicines []model.RequestedMedicine
+	for i := 0; i < len(tokens); i++ {
+		if scanIgnoreWords[tokens[i]] || numberPattern.MatchString(tokens[i]) {
+			continue
+		}
+
+		match, n := c.matchAt(tokens, i)
+		if match.Medicine == nil {
+			continue
+		}
+
+		index := c.byID[match.Medicine.ID]
+		i += n - 1
+		if seen[index] {
+			continue
+		}
+		seen[index] = true
+
+		medicine := model.RequestedMedicine{
+			Name:       match.Medicine.Name,
+			MedicineID: match.Medicine.ID,
+			Score:      match.Score,
+		}
+		i += parseDetails(tokens[i+1:], &medicine)
+		medicines = append(medicines, medicine)
+	}
+	return medicines
+}
+
+// matchAt tries the longest word sequence first, fuzzy matching only
+// sequences long enough not to produce noise
+func (c *Catalog) matchAt(tokens []string, start int) (Match, int) {
+	for n := min(maxNameWords, len(tokens)-start); n > 0; n-- {
+		key := Key(strings.Join(tokens[start:start+n], ""))
+		if k, ok := c.byKey[key]; ok {
+			return Match{Medicine: &c.medicines[k.index], Score: 1}, n
+		}
+	}
+	for n := min(maxNameWords, len(tokens)-start); n > 0; n-- {
+		key := Key(strings.Join(tokens[start:start+n], ""))
+		if len(key) < 5 {
+			continue
+		}
+		if best := c.suggest(key, 1, MinScore); len(best) > 0 {
+			return best[0], n
+		}
+	}
+	return Match{}, 0
+}
+
+// parseDetails fills dosage, form and quantity from the words following
+// the name and returns the number of consumed words
+func parseDetails(tokens []string, medicine *model.RequestedMedicine) int {
+	consumed := 0
+	for consumed < len(tokens) {
+		token := tokens[consumed]
+		switch {
+		case medicine.Dosage == "" && dosagePattern.MatchString(token):
+			m := dosagePattern.FindStringSubmatch(token)
+			medicine.Dosage = m[1] + " " + m[2]
+			consumed++
+		case numberPattern.MatchString(token) && consumed+1 < len(tokens) && dosageUnits[tokens[consumed+1]] && medicine.Dosage == "":
+			medicine.Dosage = token + " " + tokens[consumed+1]
+			consumed += 2
+		case numberPattern.MatchString(token) && consumed+1 < len(tokens) && isQuantityUnit(tokens[consumed+1]) && medicine.Quantity == 0:
+			medicine.Quantity, _ = strconv.Atoi(token)
+			consumed += 2
+		case medicine.Form == "" && isFormWord(token):
+			medicine.Form = token
+			consumed++
+		default:
+			return consumed
+		}
+	}
+	return consumed
+}
+
+func isQuantityUnit(word string) bool {
+	for _, unit := range quantityUnits {
+		if strings.HasPrefix(word, unit) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFormWord(word string) bool {
+	for _, form := range formWords {
+		if word == form {
+			return true
+		}
+	}
+	return false
+}
+
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Medicine.Name < matches[j].Medicine.Name
+	})
+}
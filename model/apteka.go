@@ -0,0 +1,66 @@
+package model
+
+import (
+	"math"
+	"time"
+
+	"github.com/oybek/jethouse/phone"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Apteka struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name,omitempty" json:"name"`
+	Phone     string             `bson:"phone,omitempty" json:"phone"`
+	Address   string             `bson:"address,omitempty" json:"address"`
+	WorkHours WorkHours          `bson:"work_hours" json:"work_hours"`
+	Location  *Point             `bson:"location,omitempty" json:"location,omitempty"`
+	ChainID   primitive.ObjectID `bson:"chain_id,omitempty" json:"-"`
+	OwnerIDs  []int64            `bson:"owner_ids,omitempty" json:"-"`
+	CreatedAt time.Time          `bson:"created_at,omitempty" json:"-"`
+	// Pending aptekas are registered by users and wait for an admin, they
+	// are not searched until approved
+	Pending bool `bson:"pending,omitempty" json:"-"`
+
+	LastInventoryUpdate time.Time `bson:"last_inventory_update,omitempty" json:"-"`
+	// reminders about stale stock sent since the last upload
+	MissedReminders int       `bson:"missed_reminders,omitempty" json:"-"`
+	RemindedAt      time.Time `bson:"reminded_at,omitempty" json:"-"`
+}
+
+func (a *Apteka) Normalize() {
+	if e164, err := phone.Parse(a.Phone, phone.DefaultCountry); err == nil {
+		a.Phone = e164
+	}
+}
+
+func (a Apteka) Validate() []FieldError {
+	var v validator
+	v.text("name", a.Name, 2, 100)
+	v.text("address", a.Address, 5, 200)
+	v.phone("phone", a.Phone)
+	if a.WorkHours.IsZero() {
+		v.add("work_hours", CodeRequired)
+	} else {
+		v.check(a.WorkHours.IsValid(), "work_hours", CodeInvalid)
+	}
+	v.check(a.Location == nil || a.Location.IsValid(), "location", CodeInvalid)
+	return v.errs
+}
+
+// InventoryAge is the time passed since the last stock upload, aptekas
+// that have never uploaded stock are counted from the registration
+func (a Apteka) InventoryAge(now time.Time) time.Duration {
+	since := a.LastInventoryUpdate
+	if since.IsZero() {
+		since = a.CreatedAt
+	}
+	if since.IsZero() {
+		return math.MaxInt64
+	}
+	return now.Sub(since)
+}
+
+func (a Apteka) IsStale(now time.Time, freshness time.Duration) bool {
+	return a.InventoryAge(now) > freshness
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Chain is a pharmacy network with several branches managed together
+type Chain struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Name       string             `bson:"name"`
+	ManagerIDs []int64            `bson:"manager_ids,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
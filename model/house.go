@@ -1,21 +1,64 @@
 package model
 
 import (
-	"github.com/google/uuid"
+	"strings"
+	"time"
+
+	"github.com/oybek/jethouse/phone"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// listing moderation statuses, only approved listings can be active
+const (
+	HouseDraft    = "draft"
+	HousePending  = "pending"
+	HouseApproved = "approved"
+	HouseRejected = "rejected"
+)
+
+// a listing gets at most this many photos, it is the size of a media group
+const MaxHousePhotos = 10
+
 type House struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	City      string             `bson:"city,omitempty" json:"city"`
-	Address   string             `bson:"address,omitempty" json:"address"`
-	Phone     string             `bson:"phone,omitempty" json:"phone"`
-	RoomCount int                `bson:"room_count,omitempty" json:"room_count"`
-	OwnerID   int64              `bson:"owner_id,omitempty" json:"owner_id"`
-	Active    bool               `bson:"active,omitempty" json:"active"`
-	PhotoIDs  []uuid.UUID        `bson:"photo_ids,omitempty" json:"photo_ids"`
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	City    string             `bson:"city,omitempty" json:"city"`
+	Address string             `bson:"address,omitempty" json:"address"`
+	// Phone is in E.164
+	Phone     string `bson:"phone,omitempty" json:"phone"`
+	RoomCount int    `bson:"room_count,omitempty" json:"room_count"`
+	// Price in soms, zero means negotiable
+	Price int `bson:"price,omitempty" json:"price"`
+	// owner and status are set by the bot, not by the web app
+	OwnerID     int64     `bson:"owner_id,omitempty" json:"-"`
+	Active      bool      `bson:"active,omitempty" json:"-"`
+	Photos      []Photo   `bson:"photos,omitempty" json:"-"`
+	CreatedAt   time.Time `bson:"created_at" json:"-"`
+	PublishedAt time.Time `bson:"published_at,omitempty" json:"-"`
+	Status      string    `bson:"status,omitempty" json:"-"`
+	// RejectReason is shown to the owner of a rejected listing
+	RejectReason string `bson:"reject_reason,omitempty" json:"-"`
+	// Flags are problems found by the automatic checks
+	Flags []string `bson:"flags,omitempty" json:"-"`
+	// PhoneVerified is set when the owner shared a Telegram contact with this number
+	PhoneVerified bool `bson:"phone_verified,omitempty" json:"-"`
+	// AlertsSent is set once subscribers were told about the listing
+	AlertsSent bool `bson:"alerts_sent,omitempty" json:"-"`
+}
+
+func (h *House) Normalize() {
+	h.City = strings.TrimSpace(h.City)
+	h.Address = strings.TrimSpace(h.Address)
+	if e164, err := phone.Parse(h.Phone, phone.DefaultCountry); err == nil {
+		h.Phone = e164
+	}
 }
 
-func (h House) IsValid() bool {
-	return h.City != "" && h.Address != "" && h.Phone != "" && h.RoomCount > 0
+func (h House) Validate() []FieldError {
+	var v validator
+	v.text("city", h.City, 2, 64)
+	v.text("address", h.Address, 5, 200)
+	v.phone("phone", h.Phone)
+	v.number("room_count", h.RoomCount, 1, 20)
+	v.number("price", h.Price, 0, 100_000_000)
+	return v.errs
 }
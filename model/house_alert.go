@@ -0,0 +1,38 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HouseAlert is a saved house search, the user is notified about new
+// listings matching it
+type HouseAlert struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	ChatID int64              `bson:"chat_id"`
+	// Slot is unique per user and limits the number of saved searches
+	Slot int `bson:"slot"`
+	// City is lower case, empty matches any city
+	City      string    `bson:"city,omitempty"`
+	RoomsMin  int       `bson:"rooms_min,omitempty"`
+	RoomsMax  int       `bson:"rooms_max,omitempty"`
+	PriceMin  int       `bson:"price_min,omitempty"`
+	PriceMax  int       `bson:"price_max,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// Matches tells whether the listing fits the criteria, zero bounds are not checked
+func (a HouseAlert) Matches(h *House) bool {
+	if a.City != "" && a.City != strings.ToLower(h.City) {
+		return false
+	}
+	if (a.RoomsMin > 0 && h.RoomCount < a.RoomsMin) || (a.RoomsMax > 0 && h.RoomCount > a.RoomsMax) {
+		return false
+	}
+	if (a.PriceMin > 0 && h.Price < a.PriceMin) || (a.PriceMax > 0 && h.Price > a.PriceMax) {
+		return false
+	}
+	return true
+}
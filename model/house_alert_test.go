@@ -0,0 +1,39 @@
+package model
+
+import "testing"
+
+func TestHouseAlertMatches(t *testing.T) {
+	house := &House{City: "Бишкек", RoomCount: 2, Price: 30000}
+	tests := []struct {
+		name  string
+		alert HouseAlert
+		want  bool
+	}{
+		{"any", HouseAlert{}, true},
+		{"city", HouseAlert{City: "бишкек"}, true},
+		{"other city", HouseAlert{City: "ош"}, false},
+		{"rooms", HouseAlert{RoomsMin: 2, RoomsMax: 3}, true},
+		{"exact rooms", HouseAlert{RoomsMin: 2, RoomsMax: 2}, true},
+		{"too few rooms", HouseAlert{RoomsMin: 3}, false},
+		{"too many rooms", HouseAlert{RoomsMax: 1}, false},
+		{"price", HouseAlert{PriceMin: 30000, PriceMax: 30000}, true},
+		{"too cheap", HouseAlert{PriceMin: 30001}, false},
+		{"too expensive", HouseAlert{PriceMax: 29999}, false},
+		{"all", HouseAlert{City: "бишкек", RoomsMin: 1, RoomsMax: 2, PriceMin: 10000, PriceMax: 40000}, true},
+		{"all but city", HouseAlert{City: "ош", RoomsMin: 1, RoomsMax: 2, PriceMin: 10000, PriceMax: 40000}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.alert.Matches(house); got != tt.want {
+			t.Errorf("%s: Matches() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	// a negotiable price is below any minimum but within any maximum
+	negotiable := &House{City: "Бишкек", RoomCount: 1}
+	if (HouseAlert{PriceMin: 1}).Matches(negotiable) {
+		t.Error("negotiable price matches a minimum price")
+	}
+	if !(HouseAlert{PriceMax: 1}).Matches(negotiable) {
+		t.Error("negotiable price does not match a maximum price")
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Inventory is the stock of a single apteka, stored as one document
+// so that it can be replaced as a whole.
+type Inventory struct {
+	AptekaID  primitive.ObjectID `bson:"_id"`
+	Items     []InventoryItem    `bson:"items"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+type InventoryItem struct {
+	Name       string             `bson:"name"`
+	Code       string             `bson:"code,omitempty"`
+	MedicineID primitive.ObjectID `bson:"medicine_id,omitempty"`
+	Quantity   int                `bson:"quantity,omitempty"` // 0 if the uploaded file has no quantities
+}
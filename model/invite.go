@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const InviteTTL = 7 * 24 * time.Hour
+
+// Invite binds the telegram user who opens the deep link to a branch or a chain
+type Invite struct {
+	Code      string             `bson:"_id"`
+	Role      Role               `bson:"role"`
+	AptekaID  primitive.ObjectID `bson:"apteka_id,omitempty"`
+	ChainID   primitive.ObjectID `bson:"chain_id,omitempty"`
+	CreatedBy int64              `bson:"created_by"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UsedBy    int64              `bson:"used_by,omitempty"`
+	UsedAt    time.Time          `bson:"used_at,omitempty"`
+}
+
+func (i Invite) Expired(now time.Time) bool {
+	return now.After(i.CreatedAt.Add(InviteTTL))
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Medicine is an entry of the medicine catalog
+type Medicine struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name     string             `bson:"name" json:"name"`
+	INN      string             `bson:"inn,omitempty" json:"inn"`
+	Brands   []string           `bson:"brands,omitempty" json:"brands"`
+	Synonyms []string           `bson:"synonyms,omitempty" json:"synonyms"`
+	Forms    []string           `bson:"forms,omitempty" json:"forms"`
+	Dosages  []string           `bson:"dosages,omitempty" json:"dosages"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ModerationSubmit  = "submit"
+	ModerationApprove = "approve"
+	ModerationReject  = "reject"
+)
+
+// ModerationEvent is an entry of the listing moderation audit log
+type ModerationEvent struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	HouseID primitive.ObjectID `bson:"house_id"`
+	// ActorID is the owner for submissions and the moderator for decisions
+	ActorID   int64     `bson:"actor_id"`
+	Action    string    `bson:"action"`
+	Reason    string    `bson:"reason,omitempty"`
+	Flags     []string  `bson:"flags,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
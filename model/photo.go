@@ -0,0 +1,15 @@
+package model
+
+// Photo is an uploaded photo stored in several sizes for galleries
+type Photo struct {
+	Original  ImageVariant `bson:"original" json:"original"`
+	Medium    ImageVariant `bson:"medium" json:"medium"`
+	Thumbnail ImageVariant `bson:"thumbnail" json:"thumbnail"`
+}
+
+// ImageVariant is a size of the photo, Key is the key in the blob store
+type ImageVariant struct {
+	Key    string `bson:"key" json:"key"`
+	Width  int    `bson:"width" json:"width"`
+	Height int    `bson:"height" json:"height"`
+}
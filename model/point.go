@@ -0,0 +1,23 @@
+package model
+
+// Point is a GeoJSON point, coordinates are [longitude, latitude]
+type Point struct {
+	Type        string     `bson:"type" json:"type"`
+	Coordinates [2]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+func NewPoint(lat, lon float64) *Point {
+	return &Point{Type: "Point", Coordinates: [2]float64{lon, lat}}
+}
+
+func (p Point) Lat() float64 {
+	return p.Coordinates[1]
+}
+
+func (p Point) Lon() float64 {
+	return p.Coordinates[0]
+}
+
+func (p Point) IsValid() bool {
+	return p.Lat() >= -90 && p.Lat() <= 90 && p.Lon() >= -180 && p.Lon() <= 180
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RequestedMedicine is a medicine the user asked for, as extracted from the voice transcript
+type RequestedMedicine struct {
+	Name       string             `bson:"name" json:"name"`
+	Dosage     string             `bson:"dosage,omitempty" json:"dosage,omitempty"`
+	Form       string             `bson:"form,omitempty" json:"form,omitempty"`
+	Quantity   int                `bson:"quantity,omitempty" json:"quantity,omitempty"`
+	MedicineID primitive.ObjectID `bson:"medicine_id,omitempty" json:"medicine_id,omitempty"`
+	Score      float64            `bson:"score,omitempty" json:"score,omitempty"`
+}
+
+// Resolved tells whether the medicine was matched to the catalog
+func (m RequestedMedicine) Resolved() bool {
+	return !m.MedicineID.IsZero()
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// SearchRequestTTL must match the TTL index on the search_requests collection.
+const SearchRequestTTL = 24 * time.Hour
+
+type SearchRequest struct {
+	ID        string              `bson:"_id"`
+	ChatID    int64               `bson:"chat_id"`
+	Text      string              `bson:"text"`
+	Medicines []RequestedMedicine `bson:"medicines"`
+	Location  *Point              `bson:"location,omitempty"`
+	CreatedAt time.Time           `bson:"created_at"`
+}
+
+func (r SearchRequest) Expired(now time.Time) bool {
+	return now.After(r.CreatedAt.Add(SearchRequestTTL))
+}
@@ -1,7 +1,53 @@
 package model
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Role string
+
+const (
+	RoleUser         Role = "user"
+	RolePharmacist   Role = "pharmacist"
+	RoleChainManager Role = "chain_manager"
+	RoleAdmin        Role = "admin"
+)
+
 type User struct {
-	ChatId   int64
-	AptekaId int64
-	Reader   string
+	ChatId   int64              `bson:"user_id"`
+	Role     Role               `bson:"role,omitempty"`
+	AptekaId primitive.ObjectID `bson:"apteka_id,omitempty"`
+	ChainId  primitive.ObjectID `bson:"chain_id,omitempty"`
+	Reader   string             `bson:"reader,omitempty"`
+	// Location shared by the user, aptekas are ranked by distance to it
+	Location   *Point    `bson:"location,omitempty"`
+	LocationAt time.Time `bson:"location_at,omitempty"`
+	// Phone is the E.164 number of the contact shared by the user
+	Phone   string    `bson:"phone,omitempty"`
+	PhoneAt time.Time `bson:"phone_at,omitempty"`
+	// Language is chosen with /language, LanguageCode is the one of the
+	// Telegram client and is used until the user chooses
+	Language     string `bson:"language,omitempty"`
+	LanguageCode string `bson:"language_code,omitempty"`
+}
+
+// IsPharmacist tells whether the user works in an apteka, users registered
+// before roles were introduced have only the apteka set
+func (u User) IsPharmacist() bool {
+	return (u.Role == RolePharmacist || u.Role == "") && !u.AptekaId.IsZero()
+}
+
+func (u User) IsChainManager() bool {
+	return u.Role == RoleChainManager && !u.ChainId.IsZero()
+}
+
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// CanManageAptekas tells whether the user can upload stock and invite pharmacists
+func (u User) CanManageAptekas() bool {
+	return u.IsPharmacist() || u.IsChainManager() || u.IsAdmin()
 }
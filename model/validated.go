@@ -3,21 +3,145 @@ package model
 import (
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/oybek/jethouse/phone"
+)
+
+// validation error codes
+const (
+	CodeRequired = "required"
+	CodeTooShort = "too_short"
+	CodeTooLong  = "too_long"
+	CodeRange    = "range"
+	CodeFormat   = "format"
+	CodeType     = "type"
+	// CodeInvalid is a well formed value that makes no sense, like an unknown timezone
+	CodeInvalid = "invalid"
 )
 
+// FieldError describes a wrong field of a web app payload, the text shown
+// to the user is made of the code and the params by the caller
+type FieldError struct {
+	Field string `json:"field"`
+	Code  string `json:"code"`
+	// Params are the bounds of too_short, too_long and range
+	Params []int `json:"params,omitempty"`
+	// Err is the cause of the error, if any, it is logged but not shown
+	Err error `json:"-"`
+}
+
+func (e *FieldError) Error() string {
+	if len(e.Params) == 0 {
+		return e.Field + ": " + e.Code
+	}
+	return fmt.Sprintf("%s: %s %v", e.Field, e.Code, e.Params)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError holds all the wrong fields of a payload
+type ValidationError struct {
+	Fields []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
 type Validated interface {
-	IsValid() bool
+	Validate() []FieldError
 }
 
+// Normalizer is implemented by types that clean up the parsed input,
+// like phone numbers, before it is validated
+type Normalizer interface {
+	Normalize()
+}
+
+// ParseAndValidate returns a *ValidationError when the payload has wrong fields
 func ParseAndValidate[T Validated](rawJSON string) (*T, error) {
 	var data T
 	if err := json.Unmarshal([]byte(rawJSON), &data); err != nil {
-		log.Printf("Unmarshal error: %s", err.Error())
+		var typeErr *json.UnmarshalTypeError
+		var fieldErr *FieldError
+		switch {
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return nil, &ValidationError{Fields: []FieldError{
+				{Field: typeErr.Field, Code: CodeType, Err: err},
+			}}
+		case errors.As(err, &fieldErr):
+			return nil, &ValidationError{Fields: []FieldError{*fieldErr}}
+		}
 		return nil, err
 	}
-	if !data.IsValid() {
-		return nil, errors.New("invalid data")
+	if n, ok := any(&data).(Normalizer); ok {
+		n.Normalize()
+	}
+	if errs := data.Validate(); len(errs) > 0 {
+		return nil, &ValidationError{Fields: errs}
 	}
 	return &data, nil
 }
+
+// WebAppType reads the "type" discriminator of a web app payload
+func WebAppType(rawJSON string) (string, error) {
+	var payload struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(rawJSON), &payload); err != nil {
+		return "", err
+	}
+	return payload.Type, nil
+}
+
+// validator collects the errors of all fields instead of stopping at the first one
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) add(field, code string, params ...int) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Params: params})
+}
+
+// text checks that the trimmed value is present and its length in runes is within the bounds
+func (v *validator) text(field, value string, min, max int) {
+	n := utf8.RuneCountInString(strings.TrimSpace(value))
+	switch {
+	case n == 0:
+		v.add(field, CodeRequired)
+	case n < min:
+		v.add(field, CodeTooShort, min)
+	case n > max:
+		v.add(field, CodeTooLong, max)
+	}
+}
+
+func (v *validator) number(field string, value, min, max int) {
+	if value < min || value > max {
+		v.add(field, CodeRange, min, max)
+	}
+}
+
+// phone expects a number normalized by phone.Parse
+func (v *validator) phone(field, value string) {
+	if value == "" {
+		v.add(field, CodeRequired)
+		return
+	}
+	v.check(phone.Valid(value), field, CodeFormat)
+}
+
+func (v *validator) check(ok bool, field, code string) {
+	if !ok {
+		v.add(field, code)
+	}
+}
@@ -0,0 +1,544 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const DefaultTimezone = "Asia/Bishkek"
+
+const minutesInDay = 24 * 60
+
+// Interval of work in minutes since midnight, an interval with To not after
+// From lasts past midnight, like 20:00-08:00
+type Interval struct {
+	From int `bson:"from" json:"from"`
+	To   int `bson:"to" json:"to"`
+}
+
+func (i Interval) overnight() bool {
+	return i.To <= i.From
+}
+
+func (i Interval) String() string {
+	return formatMinutes(i.From) + "-" + formatMinutes(i.To)
+}
+
+// WorkHours is a weekly schedule in the apteka timezone with per-date
+// exceptions, a date mapped to no intervals is a holiday
+type WorkHours struct {
+	Text       string                `bson:"text,omitempty"`
+	Timezone   string                `bson:"timezone,omitempty"`
+	Week       [7][]Interval         `bson:"week"` // Monday first
+	Exceptions map[string][]Interval `bson:"exceptions,omitempty"`
+}
+
+const dateLayout = "2006-01-02"
+
+var weekdayNames = [7]string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+var dayWords = map[string]int{
+	"пн": 0, "понедельник": 0,
+	"вт": 1, "вторник": 1,
+	"ср": 2, "среда": 2, "среду": 2,
+	"чт": 3, "четверг": 3,
+	"пт": 4, "пятница": 4, "пятницу": 4,
+	"сб": 5, "суббота": 5, "субботу": 5,
+	"вс": 6, "воскресенье": 6,
+}
+
+var workHoursToken = regexp.MustCompile(
+	`(24\s*/\s*7|круглосуточно|24 часа)` +
+		`|(ежедневно|без выходных)` +
+		`|(перерыв|обед)` +
+		`|(выходн\S*)` +
+		`|(\d{1,2})(?:[:.](\d{2}))?\s*[-–—]\s*(\d{1,2})(?:[:.](\d{2}))?` +
+		`|(понедельник|вторник|среда|среду|четверг|пятница|пятницу|суббота|субботу|воскресенье|пн|вт|ср|чт|пт|сб|вс)` +
+		`(?:\s*[-–—]\s*(понедельник|вторник|среда|среду|четверг|пятница|пятницу|суббота|субботу|воскресенье|пн|вт|ср|чт|пт|сб|вс))?`,
+)
+
+// ParseWorkHours parses russian shorthand like "Пн-Пт 9-21, Сб,Вс 9-18",
+// "Ежедневно 8:00-22:00, перерыв 13-14", "Вс выходной" or "Круглосуточно"
+func ParseWorkHours(text string) (WorkHours, error) {
+	w := WorkHours{Text: strings.TrimSpace(text), Timezone: DefaultTimezone}
+
+	var pending, last []int
+	breakNext := false
+	lower := strings.ToLower(text)
+	for _, idx := range workHoursToken.FindAllStringSubmatchIndex(lower, -1) {
+		m := submatches(lower, idx)
+		if m[9] != "" && !isWordBoundary(lower, idx[0], idx[1]) {
+			// a day abbreviation inside a word, like "вс" in "все"
+			continue
+		}
+		switch {
+		case m[1] != "":
+			for _, day := range daysOrAll(pending) {
+				w.Week[day] = []Interval{{From: 0, To: minutesInDay}}
+			}
+			last, pending = daysOrAll(pending), nil
+		case m[2] != "":
+			pending = allDays()
+		case m[3] != "":
+			breakNext = true
+		case m[4] != "":
+			for _, day := range pending {
+				w.Week[day] = nil
+			}
+			last, pending = pending, nil
+		case m[5] != "":
+			interval, err := parseInterval(m[5], m[6], m[7], m[8])
+			if err != nil {
+				return WorkHours{}, err
+			}
+			if breakNext {
+				for _, day := range last {
+					w.Week[day] = subtract(w.Week[day], interval)
+				}
+				breakNext = false
+				continue
+			}
+			days := pending
+			if days == nil {
+				days = last
+			}
+			if days == nil {
+				days = allDays()
+			}
+			for _, day := range days {
+				w.Week[day] = append(w.Week[day], interval)
+			}
+			last, pending = days, nil
+		case m[9] != "":
+			from := dayWords[m[9]]
+			to := from
+			if m[10] != "" {
+				to = dayWords[m[10]]
+			}
+			for day := from; ; day = (day + 1) % 7 {
+				pending = append(pending, day)
+				if day == to {
+					break
+				}
+			}
+		}
+	}
+
+	if w.IsZero() {
+		return WorkHours{}, fmt.Errorf("could not parse work hours %q", text)
+	}
+	return w, nil
+}
+
+func submatches(s string, idx []int) []string {
+	m := make([]string, len(idx)/2)
+	for i := range m {
+		if idx[2*i] >= 0 {
+			m[i] = s[idx[2*i]:idx[2*i+1]]
+		}
+	}
+	return m
+}
+
+func isWordBoundary(s string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(s[:start])
+	after, _ := utf8.DecodeRuneInString(s[end:])
+	return !unicode.IsLetter(before) && !unicode.IsLetter(after)
+}
+
+func parseInterval(fromH, fromM, toH, toM string) (Interval, error) {
+	from, err := toMinutes(fromH, fromM)
+	if err != nil {
+		return Interval{}, err
+	}
+	to, err := toMinutes(toH, toM)
+	if err != nil {
+		return Interval{}, err
+	}
+	if to == 0 {
+		to = minutesInDay
+	}
+	if from == to {
+		return Interval{}, fmt.Errorf("empty interval %s", Interval{From: from, To: to})
+	}
+	return Interval{From: from, To: to}, nil
+}
+
+func toMinutes(hours, minutes string) (int, error) {
+	h, _ := strconv.Atoi(hours)
+	m := 0
+	if minutes != "" {
+		m, _ = strconv.Atoi(minutes)
+	}
+	if h > 24 || m > 59 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("invalid time %s:%s", hours, minutes)
+	}
+	return h*60 + m, nil
+}
+
+// parseIntervals parses the structured form ["09:00-13:00", "14:00-21:00"]
+func parseIntervals(values []string) ([]Interval, error) {
+	intervals := make([]Interval, 0, len(values))
+	for _, value := range values {
+		m := workHoursToken.FindStringSubmatch(value)
+		if m == nil || m[5] == "" {
+			return nil, fmt.Errorf("invalid interval %q", value)
+		}
+		interval, err := parseInterval(m[5], m[6], m[7], m[8])
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, nil
+}
+
+// subtract cuts a break out of the day intervals
+func subtract(intervals []Interval, cut Interval) []Interval {
+	var result []Interval
+	for _, i := range intervals {
+		if i.overnight() || cut.To <= i.From || cut.From >= i.To {
+			result = append(result, i)
+			continue
+		}
+		if cut.From > i.From {
+			result = append(result, Interval{From: i.From, To: cut.From})
+		}
+		if cut.To < i.To {
+			result = append(result, Interval{From: cut.To, To: i.To})
+		}
+	}
+	return result
+}
+
+func allDays() []int {
+	return []int{0, 1, 2, 3, 4, 5, 6}
+}
+
+func daysOrAll(days []int) []int {
+	if days == nil {
+		return allDays()
+	}
+	return days
+}
+
+func (w WorkHours) IsZero() bool {
+	for _, day := range w.Week {
+		if len(day) > 0 {
+			return false
+		}
+	}
+	return len(w.Exceptions) == 0
+}
+
+func (w WorkHours) location() *time.Location {
+	name := w.Timezone
+	if name == "" {
+		name = DefaultTimezone
+	}
+	if location, err := time.LoadLocation(name); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// intervalsOn returns the schedule of the date, exceptions take precedence over the week
+func (w WorkHours) intervalsOn(date time.Time) []Interval {
+	if intervals, ok := w.Exceptions[date.Format(dateLayout)]; ok {
+		return intervals
+	}
+	return w.Week[(int(date.Weekday())+6)%7]
+}
+
+// IsOpenAt tells whether the apteka works at the time
+func (w WorkHours) IsOpenAt(t time.Time) bool {
+	t = t.In(w.location())
+	minute := t.Hour()*60 + t.Minute()
+
+	for _, i := range w.intervalsOn(t) {
+		if minute >= i.From && (i.overnight() || minute < i.To) {
+			return true
+		}
+	}
+	for _, i := range w.intervalsOn(t.AddDate(0, 0, -1)) {
+		if i.overnight() && minute < i.To {
+			return true
+		}
+	}
+	return false
+}
+
+// openings lists starts and ends of work intervals from the day before t for two weeks
+func (w WorkHours) openings(t time.Time) [][2]time.Time {
+	t = t.In(w.location())
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	var result [][2]time.Time
+	for d := -1; d < 14; d++ {
+		day := midnight.AddDate(0, 0, d)
+		intervals := append([]Interval(nil), w.intervalsOn(day)...)
+		sort.Slice(intervals, func(i, j int) bool { return intervals[i].From < intervals[j].From })
+		for _, i := range intervals {
+			from := day.Add(time.Duration(i.From) * time.Minute)
+			to := day.Add(time.Duration(i.To) * time.Minute)
+			if i.overnight() {
+				to = to.AddDate(0, 0, 1)
+			}
+			result = append(result, [2]time.Time{from, to})
+		}
+	}
+	return result
+}
+
+// NextOpening returns when the apteka opens next after t, or t itself if it is open
+func (w WorkHours) NextOpening(t time.Time) (time.Time, bool) {
+	if w.IsOpenAt(t) {
+		return t, true
+	}
+	for _, o := range w.openings(t) {
+		if o[0].After(t) {
+			return o[0], true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ClosesAt returns when the apteka closes if it is open at t, working
+// intervals following each other without a gap are merged
+func (w WorkHours) ClosesAt(t time.Time) (time.Time, bool) {
+	if !w.IsOpenAt(t) {
+		return time.Time{}, false
+	}
+
+	var closes time.Time
+	for _, o := range w.openings(t) {
+		switch {
+		case closes.IsZero() && !o[0].After(t) && o[1].After(t):
+			closes = o[1]
+		case !closes.IsZero() && !o[0].After(closes) && o[1].After(closes):
+			closes = o[1]
+		}
+	}
+	// open for the whole two weeks ahead
+	if closes.IsZero() || closes.Sub(t) > 7*24*time.Hour {
+		return time.Time{}, false
+	}
+	return closes, true
+}
+
+// Is24x7 tells whether the apteka works around the clock
+func (w WorkHours) Is24x7() bool {
+	for _, day := range w.Week {
+		if len(day) != 1 || day[0].From != 0 || day[0].To != minutesInDay {
+			return false
+		}
+	}
+	return true
+}
+
+func (w WorkHours) IsValid() bool {
+	if w.IsZero() {
+		return false
+	}
+	if _, err := time.LoadLocation(w.Timezone); w.Timezone != "" && err != nil {
+		return false
+	}
+	for date := range w.Exceptions {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the original text or renders the week like "Пн-Пт 9:00-21:00, Сб-Вс 9:00-18:00"
+func (w WorkHours) String() string {
+	if w.Text != "" {
+		return w.Text
+	}
+	if w.Is24x7() {
+		return "Круглосуточно"
+	}
+
+	var parts []string
+	for day := 0; day < 7; {
+		end := day
+		for end+1 < 7 && sameIntervals(w.Week[end+1], w.Week[day]) {
+			end++
+		}
+		days := weekdayNames[day]
+		if end > day {
+			days += "-" + weekdayNames[end]
+		}
+		if len(w.Week[day]) == 0 {
+			parts = append(parts, days+" выходной")
+		} else {
+			intervals := make([]string, 0, len(w.Week[day]))
+			for _, i := range w.Week[day] {
+				intervals = append(intervals, i.String())
+			}
+			parts = append(parts, days+" "+strings.Join(intervals, ", "))
+		}
+		day = end + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sameIntervals(a, b []Interval) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+// workHoursJSON is the structured form accepted from the web app:
+//
+//	{"timezone": "Asia/Bishkek", "days": {"mon": ["09:00-13:00", "14:00-21:00"], "sun": []},
+//	 "holidays": ["2025-01-01"], "exceptions": {"2025-03-08": ["10:00-16:00"]}}
+type workHoursJSON struct {
+	Text       string              `json:"text,omitempty"`
+	Timezone   string              `json:"timezone,omitempty"`
+	Days       map[string][]string `json:"days,omitempty"`
+	Holidays   []string            `json:"holidays,omitempty"`
+	Exceptions map[string][]string `json:"exceptions,omitempty"`
+}
+
+var jsonDays = [7]string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// UnmarshalJSON accepts either the shorthand string or the structured form,
+// errors are reported as a wrong work_hours field of the web app form
+func (w *WorkHours) UnmarshalJSON(data []byte) error {
+	if err := w.unmarshalJSON(data); err != nil {
+		return &FieldError{Field: "work_hours", Code: CodeFormat, Err: err}
+	}
+	return nil
+}
+
+func (w *WorkHours) unmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		parsed, err := ParseWorkHours(text)
+		if err != nil {
+			return err
+		}
+		*w = parsed
+		return nil
+	}
+
+	var raw workHoursJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	result := WorkHours{Text: raw.Text, Timezone: raw.Timezone}
+	if len(raw.Days) == 0 && raw.Text != "" {
+		parsed, err := ParseWorkHours(raw.Text)
+		if err != nil {
+			return err
+		}
+		result.Week = parsed.Week
+	}
+	for i, day := range jsonDays {
+		intervals, err := parseIntervals(raw.Days[day])
+		if err != nil {
+			return err
+		}
+		if len(intervals) > 0 {
+			result.Week[i] = intervals
+		}
+	}
+
+	if len(raw.Holidays) > 0 || len(raw.Exceptions) > 0 {
+		result.Exceptions = map[string][]Interval{}
+	}
+	for _, date := range raw.Holidays {
+		result.Exceptions[date] = []Interval{}
+	}
+	for date, values := range raw.Exceptions {
+		intervals, err := parseIntervals(values)
+		if err != nil {
+			return err
+		}
+		result.Exceptions[date] = intervals
+	}
+	if result.Timezone == "" {
+		result.Timezone = DefaultTimezone
+	}
+
+	*w = result
+	return nil
+}
+
+func (w WorkHours) MarshalJSON() ([]byte, error) {
+	raw := workHoursJSON{Text: w.String(), Timezone: w.Timezone, Days: map[string][]string{}}
+	for i, day := range jsonDays {
+		values := []string{}
+		for _, interval := range w.Week[i] {
+			values = append(values, interval.String())
+		}
+		raw.Days[day] = values
+	}
+	for date, intervals := range w.Exceptions {
+		if len(intervals) == 0 {
+			raw.Holidays = append(raw.Holidays, date)
+			continue
+		}
+		if raw.Exceptions == nil {
+			raw.Exceptions = map[string][]string{}
+		}
+		for _, interval := range intervals {
+			raw.Exceptions[date] = append(raw.Exceptions[date], interval.String())
+		}
+	}
+	sort.Strings(raw.Holidays)
+	return json.Marshal(raw)
+}
+
+// workHoursBSON has the same layout as WorkHours without its BSON methods
+type workHoursBSON WorkHours
+
+// UnmarshalBSONValue also reads aptekas created when work hours were stored as plain text
+func (w *WorkHours) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bsontype.String:
+		var text string
+		if err := bson.UnmarshalValue(t, data, &text); err != nil {
+			return err
+		}
+		parsed, err := ParseWorkHours(text)
+		if err != nil {
+			// keep the text to show it as is
+			*w = WorkHours{Text: text}
+			return nil
+		}
+		*w = parsed
+		return nil
+	case bsontype.EmbeddedDocument:
+		return bson.Unmarshal(data, (*workHoursBSON)(w))
+	case bsontype.Null, bsontype.Undefined:
+		*w = WorkHours{}
+		return nil
+	default:
+		return errors.New("unexpected bson type for work hours: " + t.String())
+	}
+}
@@ -0,0 +1,247 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func sameWeek(a, b [7][]Interval) bool {
+	for day := range a {
+		if !sameIntervals(a[day], b[day]) {
+			return false
+		}
+	}
+	return true
+}
+
+func same(i Interval) [7][]Interval {
+	var w [7][]Interval
+	for day := range w {
+		w[day] = []Interval{i}
+	}
+	return w
+}
+
+func TestParseWorkHours(t *testing.T) {
+	day := Interval{From: 9 * 60, To: 21 * 60}
+	short := Interval{From: 9 * 60, To: 18 * 60}
+	allDay := Interval{From: 0, To: minutesInDay}
+	night := Interval{From: 20 * 60, To: 8 * 60}
+
+	tests := []struct {
+		text string
+		want [7][]Interval
+	}{
+		{"Пн-Пт 9-21, Сб,Вс 9-18", [7][]Interval{{day}, {day}, {day}, {day}, {day}, {short}, {short}}},
+		{"Пн-Пт 9:00-21:00, Сб 9.00-18.00, Вс выходной", [7][]Interval{{day}, {day}, {day}, {day}, {day}, {short}, nil}},
+		{"понедельник - пятница 9—21", [7][]Interval{{day}, {day}, {day}, {day}, {day}, nil, nil}},
+		{"9-18", same(short)},
+		{"Без выходных 9:00 - 18:00", same(short)},
+		{"Все дни 9-18", same(short)},
+		{"Ежедневно 9-21, перерыв 13-14", func() [7][]Interval {
+			w := same(day)
+			for i := range w {
+				w[i] = []Interval{{From: 9 * 60, To: 13 * 60}, {From: 14 * 60, To: 21 * 60}}
+			}
+			return w
+		}()},
+		{"Круглосуточно", same(allDay)},
+		{"24/7", same(allDay)},
+		{"0-24", same(allDay)},
+		{"Ежедневно 20:00-08:00", same(night)},
+		{"Пт-Пн 20-8", [7][]Interval{{night}, nil, nil, nil, {night}, {night}, {night}}},
+		{"Пн-Сб 9-18, Вс круглосуточно", [7][]Interval{{short}, {short}, {short}, {short}, {short}, {short}, {allDay}}},
+	}
+	for _, tt := range tests {
+		w, err := ParseWorkHours(tt.text)
+		if err != nil {
+			t.Errorf("ParseWorkHours(%q) error: %v", tt.text, err)
+			continue
+		}
+		if !sameWeek(w.Week, tt.want) {
+			t.Errorf("ParseWorkHours(%q) = %v, want %v", tt.text, w.Week, tt.want)
+		}
+		if w.Text == "" || w.Timezone != DefaultTimezone {
+			t.Errorf("ParseWorkHours(%q) text %q, timezone %q", tt.text, w.Text, w.Timezone)
+		}
+	}
+}
+
+func TestParseWorkHoursMalformed(t *testing.T) {
+	for _, text := range []string{"", "по звонку", "25-30", "9:75-18", "9-9", "Вс выходной"} {
+		if w, err := ParseWorkHours(text); err == nil {
+			t.Errorf("ParseWorkHours(%q) = %v, want error", text, w.Week)
+		}
+	}
+}
+
+func utcHours(t *testing.T, text string) WorkHours {
+	t.Helper()
+	w, err := ParseWorkHours(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Timezone = "UTC"
+	return w
+}
+
+// 2025-01-06 is a Monday
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, 1, 5+day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestIsOpenAt(t *testing.T) {
+	weekdays := utcHours(t, "Пн-Пт 9-18, перерыв 13-14")
+	overnight := utcHours(t, "Пт 20:00-08:00")
+	holiday := utcHours(t, "Ежедневно 9-18")
+	holiday.Exceptions = map[string][]Interval{"2025-01-07": {}, "2025-01-08": {{From: 10 * 60, To: 12 * 60}}}
+
+	tests := []struct {
+		name string
+		w    WorkHours
+		t    time.Time
+		want bool
+	}{
+		{"opening minute", weekdays, at(1, 9, 0), true},
+		{"closing minute", weekdays, at(1, 18, 0), false},
+		{"break", weekdays, at(1, 13, 30), false},
+		{"after break", weekdays, at(1, 14, 0), true},
+		{"day off", weekdays, at(6, 12, 0), false},
+		{"overnight evening", overnight, at(5, 23, 0), true},
+		{"overnight after midnight", overnight, at(6, 7, 59), true},
+		{"overnight morning end", overnight, at(6, 8, 0), false},
+		{"overnight day before", overnight, at(5, 7, 0), false},
+		{"holiday", holiday, at(2, 12, 0), false},
+		{"short day", holiday, at(3, 11, 0), true},
+		{"short day closed", holiday, at(3, 13, 0), false},
+		{"usual day", holiday, at(4, 13, 0), true},
+	}
+	for _, tt := range tests {
+		if got := tt.w.IsOpenAt(tt.t); got != tt.want {
+			t.Errorf("%s: IsOpenAt(%s) = %t, want %t", tt.name, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestIsOpenAtTimezone(t *testing.T) {
+	w := utcHours(t, "Ежедневно 9-18")
+	w.Timezone = "Asia/Bishkek"
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		t.Skip("no timezone data")
+	}
+	// 04:00 UTC is 10:00 in Bishkek
+	if !w.IsOpenAt(at(1, 4, 0)) || w.IsOpenAt(at(1, 13, 0)) {
+		t.Errorf("IsOpenAt ignores the timezone %s", w.Timezone)
+	}
+}
+
+func TestNextOpeningAndClosesAt(t *testing.T) {
+	weekdays := utcHours(t, "Пн-Пт 9-18")
+	tests := []struct {
+		name   string
+		w      WorkHours
+		t      time.Time
+		opens  time.Time
+		closes time.Time
+	}{
+		{"weekend", weekdays, at(6, 10, 0), at(8, 9, 0), time.Time{}},
+		{"evening", weekdays, at(1, 19, 0), at(2, 9, 0), time.Time{}},
+		{"open", weekdays, at(1, 10, 0), at(1, 10, 0), at(1, 18, 0)},
+		{"merged intervals", utcHours(t, "Ежедневно 9-13, 13-18"), at(1, 10, 0), at(1, 10, 0), at(1, 18, 0)},
+		{"overnight", utcHours(t, "Ежедневно 20-8"), at(1, 23, 0), at(1, 23, 0), at(2, 8, 0)},
+		{"24/7", utcHours(t, "Круглосуточно"), at(1, 10, 0), at(1, 10, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		opens, ok := tt.w.NextOpening(tt.t)
+		if !ok || !opens.Equal(tt.opens) {
+			t.Errorf("%s: NextOpening = %s, %t, want %s", tt.name, opens, ok, tt.opens)
+		}
+		closes, ok := tt.w.ClosesAt(tt.t)
+		if ok != !tt.closes.IsZero() || !closes.Equal(tt.closes) {
+			t.Errorf("%s: ClosesAt = %s, %t, want %s", tt.name, closes, ok, tt.closes)
+		}
+	}
+
+	if _, ok := (WorkHours{Timezone: "UTC"}).NextOpening(at(1, 10, 0)); ok {
+		t.Errorf("NextOpening of an empty schedule is found")
+	}
+}
+
+func TestWorkHoursString(t *testing.T) {
+	tests := []struct {
+		w    WorkHours
+		want string
+	}{
+		{WorkHours{Text: "Пн-Пт 9-21"}, "Пн-Пт 9-21"},
+		{WorkHours{Week: same(Interval{From: 0, To: minutesInDay})}, "Круглосуточно"},
+		{WorkHours{Week: [7][]Interval{
+			{{540, 1260}}, {{540, 1260}}, {{540, 1260}}, {{540, 1260}}, {{540, 1260}}, {{600, 960}}, nil,
+		}}, "Пн-Пт 9:00-21:00, Сб 10:00-16:00, Вс выходной"},
+		{WorkHours{Week: same(Interval{From: 1200, To: 480})}, "Пн-Вс 20:00-8:00"},
+	}
+	for _, tt := range tests {
+		if got := tt.w.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestWorkHoursJSON(t *testing.T) {
+	var w WorkHours
+	data := `{"timezone": "UTC", "days": {"mon": ["09:00-13:00", "14:00-21:00"], "sun": []},
+		"holidays": ["2025-01-01"], "exceptions": {"2025-03-08": ["10:00-16:00"]}}`
+	if err := json.Unmarshal([]byte(data), &w); err != nil {
+		t.Fatal(err)
+	}
+	want := [7][]Interval{{{540, 780}, {840, 1260}}}
+	if !sameWeek(w.Week, want) || w.Timezone != "UTC" || !w.IsValid() {
+		t.Errorf("Unmarshal = %+v", w)
+	}
+	if len(w.Exceptions["2025-01-01"]) != 0 || w.Exceptions["2025-03-08"][0] != (Interval{600, 960}) {
+		t.Errorf("Exceptions = %v", w.Exceptions)
+	}
+
+	encoded, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded WorkHours
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !sameWeek(decoded.Week, w.Week) || len(decoded.Exceptions) != 2 {
+		t.Errorf("round trip %s = %+v", encoded, decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`"Ежедневно 9-18"`), &w); err != nil || !sameWeek(w.Week, same(Interval{540, 1080})) {
+		t.Errorf("Unmarshal shorthand = %+v, %v", w.Week, err)
+	}
+}
+
+func TestWorkHoursJSONMalformed(t *testing.T) {
+	for _, data := range []string{
+		`"по звонку"`,
+		`{"days": {"mon": ["9 to 18"]}}`,
+		`{"days": {"mon": ["25:00-26:00"]}}`,
+		`{"exceptions": {"2025-03-08": ["10-10"]}}`,
+		`42`,
+	} {
+		var w WorkHours
+		err := json.Unmarshal([]byte(data), &w)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != "work_hours" || fieldErr.Err == nil {
+			t.Errorf("Unmarshal(%s) error = %v, want a work_hours field error with the cause", data, err)
+		}
+	}
+
+	w := WorkHours{Timezone: "Mars/Olympus", Week: same(Interval{540, 1080})}
+	if w.IsValid() {
+		t.Errorf("IsValid() with unknown timezone")
+	}
+	w = WorkHours{Exceptions: map[string][]Interval{"08.03.2025": {}}}
+	if w.IsValid() {
+		t.Errorf("IsValid() with malformed exception date")
+	}
+}
@@ -0,0 +1,140 @@
+// Package phone normalizes phone numbers of Kyrgyzstan, Kazakhstan and
+// Russia to E.164 and renders them the way they are written locally
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+type Country string
+
+const (
+	KG Country = "KG"
+	KZ Country = "KZ"
+	RU Country = "RU"
+)
+
+// DefaultCountry is assumed for numbers written without the country code
+var DefaultCountry = KG
+
+var ErrInvalid = errors.New("invalid phone number")
+
+// ParseCountry reads a country code like "kg", the empty string is the default country
+func ParseCountry(s string) (Country, error) {
+	switch c := Country(strings.ToUpper(s)); c {
+	case "":
+		return DefaultCountry, nil
+	case KG, KZ, RU:
+		return c, nil
+	}
+	return "", errors.New("unsupported country " + s)
+}
+
+// Parse normalizes the number to E.164 like +996555123456, numbers without
+// the country code are taken as national numbers of the country
+func Parse(raw string, country Country) (string, error) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+	digits := onlyDigits(raw)
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+
+	// contacts shared in Telegram come with the country code but without the plus
+	if !international {
+		switch {
+		case len(digits) == 12 && strings.HasPrefix(digits, "996"),
+			len(digits) == 11 && strings.HasPrefix(digits, "7"):
+			international = true
+		}
+	}
+
+	var e164 string
+	switch {
+	case international:
+		e164 = "+" + digits
+	case country == KG:
+		// the trunk prefix is 0 in Kyrgyzstan
+		nsn := strings.TrimPrefix(digits, "0")
+		if len(digits) == 10 && nsn == digits {
+			return "", ErrInvalid
+		}
+		e164 = "+996" + nsn
+	case country == KZ, country == RU:
+		// and 8 in Kazakhstan and Russia
+		nsn := digits
+		if len(digits) == 11 && strings.HasPrefix(digits, "8") {
+			nsn = digits[1:]
+		}
+		e164 = "+7" + nsn
+	default:
+		return "", ErrInvalid
+	}
+
+	if _, ok := countryOf(e164); !ok {
+		return "", ErrInvalid
+	}
+	return e164, nil
+}
+
+// Valid tells whether the number is a normalized E.164 number of a supported country
+func Valid(e164 string) bool {
+	_, ok := countryOf(e164)
+	return ok
+}
+
+// CountryOf returns the country of a normalized number
+func CountryOf(e164 string) (Country, error) {
+	country, ok := countryOf(e164)
+	if !ok {
+		return "", ErrInvalid
+	}
+	return country, nil
+}
+
+// Format renders the number like 0555 123 456 or 8 (701) 123-45-67,
+// numbers that are not normalized are returned as is
+func Format(e164 string) string {
+	country, ok := countryOf(e164)
+	if !ok {
+		return e164
+	}
+	if country == KG {
+		nsn := e164[4:]
+		return "0" + nsn[:3] + " " + nsn[3:6] + " " + nsn[6:]
+	}
+	nsn := e164[2:]
+	return "8 (" + nsn[:3] + ") " + nsn[3:6] + "-" + nsn[6:8] + "-" + nsn[8:]
+}
+
+func countryOf(e164 string) (Country, bool) {
+	if !strings.HasPrefix(e164, "+") || onlyDigits(e164) != e164[1:] {
+		return "", false
+	}
+	switch {
+	// national significant numbers are 9 digits in Kyrgyzstan
+	case strings.HasPrefix(e164, "+996") && len(e164) == 13 && e164[4] >= '2':
+		return KG, true
+	// and 10 digits in the +7 zone, Kazakhstan has the 6xx and 7xx codes
+	case strings.HasPrefix(e164, "+7") && len(e164) == 12:
+		switch e164[2] {
+		case '6', '7':
+			return KZ, true
+		case '3', '4', '8', '9':
+			return RU, true
+		}
+	}
+	return "", false
+}
+
+func onlyDigits(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,66 @@
+package phone
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw     string
+		country Country
+		want    string
+	}{
+		{"0555 123 456", KG, "+996555123456"},
+		{"555123456", KG, "+996555123456"},
+		{"+996 (555) 12-34-56", KG, "+996555123456"},
+		{"00996555123456", KG, "+996555123456"},
+		{"996555123456", RU, "+996555123456"},
+		{"8 (701) 123-45-67", KZ, "+77011234567"},
+		{"8 916 123 45 67", RU, "+79161234567"},
+		{"9161234567", RU, "+79161234567"},
+		{"79161234567", KG, "+79161234567"},
+		{"+7 701 123 45 67", KG, "+77011234567"},
+		{"12345", KG, ""},
+		{"5551234567", KG, ""},
+		{"+1 202 555 0100", KG, ""},
+		{"+7 501 123 45 67", RU, ""},
+		{"телефон", KG, ""},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.raw, tt.country)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Parse(%q, %s) = %q, want error", tt.raw, tt.country, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q, %s) = %q, %v, want %q", tt.raw, tt.country, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := map[string]string{
+		"+996555123456": "0555 123 456",
+		"+77011234567":  "8 (701) 123-45-67",
+		"+79161234567":  "8 (916) 123-45-67",
+		"0555 12 34 56": "0555 12 34 56",
+	}
+	for e164, want := range tests {
+		if got := Format(e164); got != want {
+			t.Errorf("Format(%q) = %q, want %q", e164, got, want)
+		}
+	}
+}
+
+func TestCountryOf(t *testing.T) {
+	tests := map[string]Country{
+		"+996555123456": KG,
+		"+77011234567":  KZ,
+		"+79161234567":  RU,
+	}
+	for e164, want := range tests {
+		if got, err := CountryOf(e164); err != nil || got != want {
+			t.Errorf("CountryOf(%q) = %s, %v, want %s", e164, got, err, want)
+		}
+	}
+}
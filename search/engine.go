@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/oybek/jethouse/medicine"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Engine keeps the search index and rebuilds it from MongoDB
+type Engine struct {
+	database *mongo.Database
+	index    atomic.Pointer[Index]
+}
+
+func NewEngine(database *mongo.Database) *Engine {
+	e := &Engine{database: database}
+	e.index.Store(NewBuilder().Build())
+	return e
+}
+
+func (e *Engine) Search(q Query) []Result {
+	return e.index.Load().Search(q)
+}
+
+func (e *Engine) Index() *Index {
+	return e.index.Load()
+}
+
+// Reload rebuilds the index from the approved aptekas and the inventories,
+// inventory items without catalog id are resolved by exact name
+func (e *Engine) Reload(ctx context.Context, catalog *medicine.Catalog) error {
+	started := time.Now()
+	builder := NewBuilder()
+
+	cursor, err := e.database.Collection("aptekas").Find(ctx, bson.M{"pending": bson.M{"$ne": true}})
+	if err != nil {
+		return err
+	}
+	var aptekas []model.Apteka
+	if err = cursor.All(ctx, &aptekas); err != nil {
+		return err
+	}
+	for _, apteka := range aptekas {
+		builder.AddApteka(apteka)
+	}
+
+	cursor, err = e.database.Collection("inventories").Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	items := 0
+	for cursor.Next(ctx) {
+		var inventory model.Inventory
+		if err := cursor.Decode(&inventory); err != nil {
+			return err
+		}
+		for _, item := range inventory.Items {
+			medicineID := item.MedicineID
+			if medicineID.IsZero() {
+				match, ok := catalog.Resolve(item.Name)
+				if !ok || match.Score < 1 {
+					continue
+				}
+				medicineID = match.Medicine.ID
+			}
+			builder.AddStock(inventory.AptekaID, medicineID, item.Name)
+			items++
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	e.index.Store(builder.Build())
+	log.Printf("Rebuilt search index: %d aptekas, %d items in %s", len(aptekas), items, time.Since(started))
+	return nil
+}
@@ -0,0 +1,257 @@
+package search
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Index is an immutable in-memory inverted index from catalog medicines
+// to the aptekas having them in stock
+type Index struct {
+	aptekas  []model.Apteka
+	postings map[primitive.ObjectID][]int32
+	names    map[primitive.ObjectID]string
+	popular  []primitive.ObjectID
+}
+
+// how many of the most stocked medicines are kept in the index
+const popularLimit = 100
+
+type Query struct {
+	MedicineIDs []primitive.ObjectID
+	// Generics of the requested medicines, an apteka without the requested
+	// medicine is found if it has one of them
+	Generics map[primitive.ObjectID][]primitive.ObjectID
+	// Location of the user, aptekas are not ranked by distance if nil
+	Location *model.Point
+	Now      time.Time
+	// Freshness of stock, aptekas with older stock are ranked after the
+	// fresh ones and twice older are not shown, 0 disables the check
+	Freshness time.Duration
+	// Limit of returned results, 0 means no limit
+	Limit int
+}
+
+type Result struct {
+	Apteka *model.Apteka
+	// Medicines are the stocked ones, a generic in place of the requested
+	Medicines []primitive.ObjectID
+	// Substitutes counts the requested medicines found as a generic
+	Substitutes int
+	// Distance to the user in meters, 0 if the location is unknown
+	Distance float64
+	Open     bool
+	Stale    bool
+	// Located tells whether the Distance is known
+	Located bool
+}
+
+// Builder collects aptekas and their stock into an Index
+type Builder struct {
+	index    *Index
+	byApteka map[primitive.ObjectID]int32
+}
+
+func NewBuilder() *Builder {
+	return &Builder{
+		index: &Index{
+			postings: map[primitive.ObjectID][]int32{},
+			names:    map[primitive.ObjectID]string{},
+		},
+		byApteka: map[primitive.ObjectID]int32{},
+	}
+}
+
+func (b *Builder) AddApteka(apteka model.Apteka) {
+	if _, ok := b.byApteka[apteka.ID]; ok {
+		return
+	}
+	b.byApteka[apteka.ID] = int32(len(b.index.aptekas))
+	b.index.aptekas = append(b.index.aptekas, apteka)
+}
+
+// AddStock marks the medicine as available in the apteka, the apteka must be added before
+func (b *Builder) AddStock(aptekaID, medicineID primitive.ObjectID, name string) {
+	i, ok := b.byApteka[aptekaID]
+	if !ok {
+		return
+	}
+
+	postings := b.index.postings[medicineID]
+	if n := len(postings); n > 0 && postings[n-1] == i {
+		return
+	}
+	b.index.postings[medicineID] = append(postings, i)
+	if _, ok := b.index.names[medicineID]; !ok {
+		b.index.names[medicineID] = name
+	}
+}
+
+func (b *Builder) Build() *Index {
+	for id, postings := range b.index.postings {
+		sort.Slice(postings, func(i, j int) bool { return postings[i] < postings[j] })
+		b.index.postings[id] = compact(postings)
+	}
+
+	popular := make([]primitive.ObjectID, 0, len(b.index.postings))
+	for id := range b.index.postings {
+		popular = append(popular, id)
+	}
+	sort.Slice(popular, func(i, j int) bool {
+		x, y := len(b.index.postings[popular[i]]), len(b.index.postings[popular[j]])
+		if x != y {
+			return x > y
+		}
+		return bytes.Compare(popular[i][:], popular[j][:]) < 0
+	})
+	b.index.popular = popular[:min(len(popular), popularLimit)]
+	return b.index
+}
+
+func compact(postings []int32) []int32 {
+	result := postings[:0]
+	for i, p := range postings {
+		if i == 0 || p != postings[i-1] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func (ix *Index) Len() int {
+	return len(ix.aptekas)
+}
+
+// Popular returns the medicines stocked in the most aptekas
+func (ix *Index) Popular() []primitive.ObjectID {
+	return ix.popular
+}
+
+// Name returns the name under which the medicine is stocked
+func (ix *Index) Name(medicineID primitive.ObjectID) string {
+	return ix.names[medicineID]
+}
+
+// Search returns aptekas having at least one of the requested medicines or
+// their generics, ordered by the number of found medicines, fewer generics
+// first, fresh stock first, then by distance rounded to distanceStep with
+// aptekas of unknown location last, then open ones first, then by distance
+func (ix *Index) Search(q Query) []Result {
+	if ix == nil {
+		return nil
+	}
+
+	positions := map[int32]int{}
+	hidden := map[int32]bool{}
+	var results []Result
+	for _, medicineID := range dedupe(q.MedicineIDs) {
+		// the requested medicine goes first, so a generic is taken only
+		// by the aptekas not having it
+		found := map[int32]bool{}
+		for _, stocked := range append([]primitive.ObjectID{medicineID}, q.Generics[medicineID]...) {
+			for _, i := range ix.postings[stocked] {
+				if hidden[i] || found[i] {
+					continue
+				}
+				pos, ok := positions[i]
+				if !ok {
+					if q.Freshness > 0 && ix.aptekas[i].IsStale(q.Now, 2*q.Freshness) {
+						hidden[i] = true
+						continue
+					}
+					pos = len(results)
+					positions[i] = pos
+					results = append(results, Result{Apteka: &ix.aptekas[i]})
+				}
+				found[i] = true
+				results[pos].Medicines = append(results[pos].Medicines, stocked)
+				if stocked != medicineID {
+					results[pos].Substitutes++
+				}
+			}
+		}
+	}
+
+	for i := range results {
+		if q.Location != nil && results[i].Apteka.Location != nil {
+			results[i].Distance = distance(*q.Location, *results[i].Apteka.Location)
+			results[i].Located = true
+		}
+		results[i].Open = isOpen(results[i].Apteka, q.Now)
+		results[i].Stale = q.Freshness > 0 && results[i].Apteka.IsStale(q.Now, q.Freshness)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if len(a.Medicines) != len(b.Medicines) {
+			return len(a.Medicines) > len(b.Medicines)
+		}
+		if a.Substitutes != b.Substitutes {
+			return a.Substitutes < b.Substitutes
+		}
+		if a.Stale != b.Stale {
+			return b.Stale
+		}
+		if a.Located != b.Located {
+			return a.Located
+		}
+		// an open apteka goes before a closed one about as near
+		if x, y := distanceBucket(a.Distance), distanceBucket(b.Distance); x != y {
+			return x < y
+		}
+		if a.Open != b.Open {
+			return a.Open
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.Apteka.Name < b.Apteka.Name
+	})
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+func dedupe(ids []primitive.ObjectID) []primitive.ObjectID {
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	result := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if !id.IsZero() && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// isOpen reports whether the apteka works at the time, aptekas
+// with unknown work hours are considered open
+func isOpen(apteka *model.Apteka, t time.Time) bool {
+	return apteka.WorkHours.IsZero() || apteka.WorkHours.IsOpenAt(t)
+}
+
+const earthRadius = 6371000
+
+// distances differing by less than this are about the same for the user
+const distanceStep = 500
+
+func distanceBucket(meters float64) int {
+	return int(meters / distanceStep)
+}
+
+// distance is the haversine distance between points in meters
+func distance(a, b model.Point) float64 {
+	lat1, lat2 := a.Lat()*math.Pi/180, b.Lat()*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon() - a.Lon()) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,305 @@
+package search
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	aspirin   = primitive.NewObjectID()
+	ibuprofen = primitive.NewObjectID()
+	nurofen   = primitive.NewObjectID()
+)
+
+// testIndex builds aptekas in Bishkek, the one without location is the
+// only apteka stocking all the medicines
+func testIndex(now time.Time) *Index {
+	builder := NewBuilder()
+	add := func(apteka model.Apteka, medicines ...primitive.ObjectID) {
+		apteka.ID = primitive.NewObjectID()
+		if apteka.LastInventoryUpdate.IsZero() {
+			apteka.LastInventoryUpdate = now
+		}
+		builder.AddApteka(apteka)
+		for _, id := range medicines {
+			builder.AddStock(apteka.ID, id, "")
+		}
+	}
+	add(model.Apteka{Name: "Далекая", Location: model.NewPoint(42.90, 74.70)}, aspirin, ibuprofen)
+	add(model.Apteka{Name: "Ближняя", Location: model.NewPoint(42.871, 74.591)}, aspirin, ibuprofen)
+	add(model.Apteka{Name: "Без адреса"}, aspirin, ibuprofen, nurofen)
+	add(model.Apteka{Name: "Неизвестная"}, aspirin, ibuprofen)
+	add(model.Apteka{Name: "Старая", Location: model.NewPoint(42.8705, 74.5905), LastInventoryUpdate: now.Add(-4 * 24 * time.Hour)}, aspirin, ibuprofen)
+	add(model.Apteka{Name: "Заброшенная", Location: model.NewPoint(42.870, 74.590), LastInventoryUpdate: now.Add(-30 * 24 * time.Hour)}, aspirin, ibuprofen)
+	return builder.Build()
+}
+
+func names(results []Result) []string {
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Apteka.Name)
+	}
+	return names
+}
+
+func TestSearchRanking(t *testing.T) {
+	now := time.Now()
+	index := testIndex(now)
+	user := model.NewPoint(42.87, 74.59)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{
+			name:  "nearest first, unknown location last",
+			query: Query{MedicineIDs: []primitive.ObjectID{aspirin, ibuprofen}, Location: user, Now: now, Freshness: 72 * time.Hour},
+			want:  []string{"Ближняя", "Далекая", "Без адреса", "Неизвестная", "Старая"},
+		},
+		{
+			name:  "more found medicines first",
+			query: Query{MedicineIDs: []primitive.ObjectID{aspirin, nurofen}, Location: user, Now: now, Freshness: 72 * time.Hour},
+			want:  []string{"Без адреса", "Ближняя", "Далекая", "Неизвестная", "Старая"},
+		},
+		{
+			name:  "without location by name",
+			query: Query{MedicineIDs: []primitive.ObjectID{aspirin}, Now: now, Freshness: 72 * time.Hour},
+			want:  []string{"Без адреса", "Ближняя", "Далекая", "Неизвестная", "Старая"},
+		},
+		{
+			name:  "freshness disabled",
+			query: Query{MedicineIDs: []primitive.ObjectID{aspirin}, Location: user, Now: now},
+			want:  []string{"Заброшенная", "Старая", "Ближняя", "Далекая", "Без адреса", "Неизвестная"},
+		},
+		{
+			name:  "limit",
+			query: Query{MedicineIDs: []primitive.ObjectID{aspirin}, Location: user, Now: now, Freshness: 72 * time.Hour, Limit: 2},
+			want:  []string{"Ближняя", "Далекая"},
+		},
+		{
+			name:  "nothing requested",
+			query: Query{MedicineIDs: []primitive.ObjectID{primitive.NilObjectID, primitive.NewObjectID()}, Location: user, Now: now},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(index.Search(tt.query))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchGenerics(t *testing.T) {
+	now := time.Now()
+	index := testIndex(now)
+
+	// only "Без адреса" has nurofen, the rest have its generic ibuprofen
+	results := index.Search(Query{
+		MedicineIDs: []primitive.ObjectID{nurofen},
+		Generics:    map[primitive.ObjectID][]primitive.ObjectID{nurofen: {ibuprofen}},
+		Location:    model.NewPoint(42.87, 74.59),
+		Now:         now,
+		Freshness:   72 * time.Hour,
+	})
+	want := []string{"Без адреса", "Ближняя", "Далекая", "Неизвестная", "Старая"}
+	if got := names(results); !slices.Equal(got, want) {
+		t.Fatalf("Search() = %q, want %q", got, want)
+	}
+	for i, r := range results {
+		stocked, substitutes := ibuprofen, 1
+		if i == 0 {
+			stocked, substitutes = nurofen, 0
+		}
+		if !slices.Equal(r.Medicines, []primitive.ObjectID{stocked}) || r.Substitutes != substitutes {
+			t.Errorf("%s: medicines %v, substitutes %d", r.Apteka.Name, r.Medicines, r.Substitutes)
+		}
+	}
+
+	// an apteka having the requested medicine and its generic counts it once
+	results = index.Search(Query{
+		MedicineIDs: []primitive.ObjectID{ibuprofen},
+		Generics:    map[primitive.ObjectID][]primitive.ObjectID{ibuprofen: {nurofen}},
+		Now:         now,
+	})
+	for _, r := range results {
+		if !slices.Equal(r.Medicines, []primitive.ObjectID{ibuprofen}) || r.Substitutes != 0 {
+			t.Errorf("%s: medicines %v, substitutes %d", r.Apteka.Name, r.Medicines, r.Substitutes)
+		}
+	}
+}
+
+func TestSearchResultFields(t *testing.T) {
+	now := time.Now()
+	index := testIndex(now)
+
+	// duplicate ids are counted once
+	results := index.Search(Query{
+		MedicineIDs: []primitive.ObjectID{aspirin, aspirin, ibuprofen},
+		Location:    model.NewPoint(42.87, 74.59),
+		Now:         now,
+		Freshness:   72 * time.Hour,
+	})
+	for _, r := range results {
+		if len(r.Medicines) != 2 {
+			t.Errorf("%s: found %d medicines, want 2", r.Apteka.Name, len(r.Medicines))
+		}
+		if located := r.Apteka.Location != nil; r.Located != located || (located && r.Distance <= 0) || (!located && r.Distance != 0) {
+			t.Errorf("%s: distance %f, located %v", r.Apteka.Name, r.Distance, r.Located)
+		}
+		if stale := r.Apteka.Name == "Старая"; r.Stale != stale {
+			t.Errorf("%s: stale %v, want %v", r.Apteka.Name, r.Stale, stale)
+		}
+		if !r.Open {
+			t.Errorf("%s: apteka without work hours is not open", r.Apteka.Name)
+		}
+	}
+
+	for _, r := range index.Search(Query{MedicineIDs: []primitive.ObjectID{aspirin}, Now: now}) {
+		if r.Located || r.Distance != 0 {
+			t.Errorf("%s: located without the user location", r.Apteka.Name)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// one degree of latitude is about 111 km
+	d := distance(*model.NewPoint(42, 74), *model.NewPoint(43, 74))
+	if d < 111_000 || d > 111_400 {
+		t.Errorf("distance = %f, want about 111 km", d)
+	}
+	if d := distance(*model.NewPoint(42, 74), *model.NewPoint(42, 74)); d != 0 {
+		t.Errorf("distance to itself = %f", d)
+	}
+}
+
+const (
+	benchAptekas     = 10_000
+	benchSKUs        = 50_000
+	benchStockPerSKU = 1_000
+)
+
+var (
+	benchOnce      sync.Once
+	benchIndex     *Index
+	benchMedicines []primitive.ObjectID
+)
+
+// benchmarkIndex builds 10k aptekas with 50k SKUs, every apteka stocking 1k random SKUs
+func benchmarkIndex(b *testing.B) *Index {
+	benchOnce.Do(func() {
+		rnd := rand.New(rand.NewSource(1))
+		benchMedicines = make([]primitive.ObjectID, benchSKUs)
+		for i := range benchMedicines {
+			benchMedicines[i] = primitive.NewObjectID()
+		}
+
+		builder := NewBuilder()
+		for i := 0; i < benchAptekas; i++ {
+			apteka := model.Apteka{
+				ID:       primitive.NewObjectID(),
+				Name:     "Аптека №" + strconv.Itoa(i),
+				Location: model.NewPoint(42.8+rnd.Float64()*0.1, 74.5+rnd.Float64()*0.1),
+			}
+			builder.AddApteka(apteka)
+			for j := 0; j < benchStockPerSKU; j++ {
+				builder.AddStock(apteka.ID, benchMedicines[rnd.Intn(benchSKUs)], "")
+			}
+		}
+		benchIndex = builder.Build()
+	})
+	return benchIndex
+}
+
+func benchmarkSearch(b *testing.B, medicines int, location *model.Point) {
+	index := benchmarkIndex(b)
+	rnd := rand.New(rand.NewSource(2))
+	queries := make([]Query, 64)
+	for i := range queries {
+		queries[i] = Query{Location: location, Now: time.Now(), Limit: 100}
+		for j := 0; j < medicines; j++ {
+			queries[i].MedicineIDs = append(queries[i].MedicineIDs, benchMedicines[rnd.Intn(benchSKUs)])
+		}
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		index.Search(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkSearch1(b *testing.B) {
+	benchmarkSearch(b, 1, nil)
+}
+
+func BenchmarkSearch5(b *testing.B) {
+	benchmarkSearch(b, 5, nil)
+}
+
+func BenchmarkSearch5Location(b *testing.B) {
+	benchmarkSearch(b, 5, model.NewPoint(42.87, 74.59))
+}
+
+func BenchmarkSearch20Location(b *testing.B) {
+	benchmarkSearch(b, 20, model.NewPoint(42.87, 74.59))
+}
+
+func BenchmarkBuild(b *testing.B) {
+	rnd := rand.New(rand.NewSource(3))
+	medicines := make([]primitive.ObjectID, benchSKUs)
+	for i := range medicines {
+		medicines[i] = primitive.NewObjectID()
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		builder := NewBuilder()
+		for i := 0; i < benchAptekas; i++ {
+			id := primitive.NewObjectID()
+			builder.AddApteka(model.Apteka{ID: id})
+			for j := 0; j < benchStockPerSKU; j++ {
+				builder.AddStock(id, medicines[rnd.Intn(benchSKUs)], "")
+			}
+		}
+		builder.Build()
+	}
+}
+
+func TestSearchOpenFirst(t *testing.T) {
+	// 2025-01-06 is a Monday
+	now := time.Date(2025, 1, 6, 20, 0, 0, 0, time.UTC)
+	dayOnly, err := model.ParseWorkHours("Пн-Вс 9:00-18:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dayOnly.Timezone = "UTC"
+
+	builder := NewBuilder()
+	add := func(apteka model.Apteka) {
+		apteka.ID = primitive.NewObjectID()
+		apteka.LastInventoryUpdate = now
+		builder.AddApteka(apteka)
+		builder.AddStock(apteka.ID, aspirin, "")
+	}
+	add(model.Apteka{Name: "Закрытая рядом", Location: model.NewPoint(42.8701, 74.5901), WorkHours: dayOnly})
+	add(model.Apteka{Name: "Открытая рядом", Location: model.NewPoint(42.8710, 74.5910)})
+	add(model.Apteka{Name: "Открытая далеко", Location: model.NewPoint(42.90, 74.70)})
+	index := builder.Build()
+
+	got := names(index.Search(Query{MedicineIDs: []primitive.ObjectID{aspirin}, Location: model.NewPoint(42.87, 74.59), Now: now}))
+	want := []string{"Открытая рядом", "Закрытая рядом", "Открытая далеко"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Search() = %q, want %q", got, want)
+	}
+}
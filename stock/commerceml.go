@@ -0,0 +1,104 @@
+package stock
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// commerceMLOffer is an offer (Предложение) of offers.xml or a product (Товар) of import.xml
+type commerceMLOffer struct {
+	ID       string `xml:"Ид"`
+	Article  string `xml:"Артикул"`
+	Name     string `xml:"Наименование"`
+	Quantity string `xml:"Количество"`
+	Stores   []struct {
+		Quantity string `xml:"КоличествоНаСкладе,attr"`
+	} `xml:"Склад"`
+}
+
+// ParseCommerceML parses a CommerceML 2 exchange file exported from 1C,
+// offers are read with their quantities, products of a catalog without
+// offers are taken as in stock with unknown quantity
+func ParseCommerceML(r io.Reader) (*Result, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if strings.EqualFold(charset, "windows-1251") || strings.EqualFold(charset, "cp1251") {
+			return charmap.Windows1251.NewDecoder().Reader(input), nil
+		}
+		return nil, errors.New("unsupported charset " + charset)
+	}
+
+	result := &Result{}
+	found := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "КоммерческаяИнформация" {
+			found = true
+		}
+		if start.Name.Local != "Предложение" && start.Name.Local != "Товар" {
+			continue
+		}
+
+		var offer commerceMLOffer
+		if err := decoder.DecodeElement(&offer, &start); err != nil {
+			return nil, err
+		}
+		if item, ok := offer.item(result); ok {
+			result.Items = append(result.Items, item)
+		}
+	}
+	if !found {
+		return nil, errors.New("not a CommerceML file")
+	}
+	return result, nil
+}
+
+func (o commerceMLOffer) item(result *Result) (Item, bool) {
+	name := strings.TrimSpace(o.Name)
+	if name == "" {
+		return Item{}, false
+	}
+
+	code := strings.TrimSpace(o.Article)
+	if code == "" {
+		code = strings.TrimSpace(o.ID)
+	}
+	item := Item{Code: code, Name: name}
+
+	switch {
+	case o.Quantity != "":
+		quantity, err := parseQuantity(o.Quantity)
+		if err != nil {
+			result.Errors = append(result.Errors, LineError{Name: name, Code: CodeQuantity, Value: strings.TrimSpace(o.Quantity)})
+			return Item{}, false
+		}
+		item.Quantity = quantity
+	case len(o.Stores) > 0:
+		for _, store := range o.Stores {
+			quantity, err := parseQuantity(store.Quantity)
+			if err != nil {
+				result.Errors = append(result.Errors, LineError{Name: name, Code: CodeQuantity, Value: strings.TrimSpace(store.Quantity)})
+				return Item{}, false
+			}
+			item.Quantity += quantity
+		}
+	default:
+		return item, true
+	}
+	return item, item.Quantity > 0
+}
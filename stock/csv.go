@@ -0,0 +1,50 @@
+package stock
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ParseCSV parses a stock list saved from 1C as CSV, both UTF-8 and
+// Windows-1251 encodings and ";", "," or tab separators are supported
+func ParseCSV(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		if data, err = charmap.Windows1251.NewDecoder().Bytes(data); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectSeparator(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return parseTable(rows)
+}
+
+func detectSeparator(data []byte) rune {
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	best, bestCount := ';', 0
+	for _, separator := range []rune{';', '\t', ','} {
+		if count := bytes.Count(firstLine, []byte(string(separator))); count > bestCount {
+			best, bestCount = separator, count
+		}
+	}
+	return best
+}
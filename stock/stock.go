@@ -0,0 +1,154 @@
+package stock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Item is a single stock line of a pharmacy export
+type Item struct {
+	Code string
+	Name string
+	// Quantity in packages, 0 if the export does not tell it
+	Quantity int
+}
+
+// Result of parsing an export, lines which could not be parsed are
+// reported in Errors and skipped
+type Result struct {
+	Items  []Item
+	Errors []LineError
+}
+
+// error codes of the skipped lines
+const (
+	CodeQuantity = "quantity"
+)
+
+// LineError tells which line is skipped and why, the text for the user is
+// made of the code by the caller
+type LineError struct {
+	// Line of a table export, 0 for CommerceML where the Name is known instead
+	Line  int
+	Name  string
+	Code  string
+	Value string
+}
+
+func (e LineError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s %q", e.Line, e.Code, e.Value)
+	}
+	return fmt.Sprintf("%s: %s %q", e.Name, e.Code, e.Value)
+}
+
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+var errQuantity = errors.New("malformed quantity")
+
+// Parse picks the parser by the file extension
+func Parse(fileName string, r io.Reader) (*Result, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xml":
+		return ParseCommerceML(r)
+	case ".csv", ".txt":
+		return ParseCSV(r)
+	case ".xlsx":
+		return ParseXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+var (
+	nameColumns     = []string{"наименование", "название", "номенклатура", "товар", "name", "product"}
+	codeColumns     = []string{"код", "артикул", "code", "sku", "ид"}
+	quantityColumns = []string{"количество", "остаток", "кол-во", "колво", "кол.", "quantity", "qty", "stock"}
+)
+
+// parseTable maps rows of a CSV or XLSX export to items, the header
+// is the first row having a name column. Without a quantity column every
+// line is in stock with unknown quantity, with it sold out lines are skipped
+func parseTable(rows [][]string) (*Result, error) {
+	nameIdx, codeIdx, quantityIdx := -1, -1, -1
+	header := 0
+	for ; header < len(rows); header++ {
+		nameIdx, codeIdx, quantityIdx = -1, -1, -1
+		for i, cell := range rows[header] {
+			cell = strings.ToLower(strings.TrimSpace(cell))
+			switch {
+			case nameIdx < 0 && hasPrefix(cell, nameColumns):
+				nameIdx = i
+			case codeIdx < 0 && hasPrefix(cell, codeColumns):
+				codeIdx = i
+			case quantityIdx < 0 && hasPrefix(cell, quantityColumns):
+				quantityIdx = i
+			}
+		}
+		if nameIdx >= 0 {
+			break
+		}
+	}
+	if nameIdx < 0 {
+		return nil, errors.New("no name column in the header")
+	}
+
+	result := &Result{}
+	for i, row := range rows[header+1:] {
+		line := header + i + 2
+		name := cell(row, nameIdx)
+		if name == "" {
+			continue
+		}
+
+		item := Item{Name: name, Code: cell(row, codeIdx)}
+		if quantityIdx >= 0 {
+			value := cell(row, quantityIdx)
+			quantity, err := parseQuantity(value)
+			if err != nil {
+				result.Errors = append(result.Errors, LineError{Line: line, Name: name, Code: CodeQuantity, Value: value})
+				continue
+			}
+			if quantity <= 0 {
+				continue
+			}
+			item.Quantity = quantity
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+func hasPrefix(cell string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(cell, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// parseQuantity accepts 1C number formats like "12", "12,000" and "1 200",
+// fractional quantities of opened packages are truncated
+func parseQuantity(s string) (int, error) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errQuantity
+	}
+	return int(f), nil
+}
@@ -0,0 +1,199 @@
+package stock
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+		err  bool
+	}{
+		{"12", 12, false},
+		{"12,000", 12, false},
+		{"1 200", 1200, false},
+		{"1 200,5", 1200, false},
+		{"", 0, false},
+		{"много", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseQuantity(tt.s)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("parseQuantity(%q) = %d, %v", tt.s, got, err)
+		}
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	result, err := parseTable([][]string{
+		{"Отчет по остаткам"},
+		{},
+		{"Код", "Наименование", "Остаток"},
+		{"001", "Нурофен 200 мг", "5"},
+		{"002", "Парацетамол", "0"},
+		{"003", "Аспирин", "два"},
+		{"004", "", "3"},
+		{"005", "Но-шпа", "2,000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{
+		{Code: "001", Name: "Нурофен 200 мг", Quantity: 5},
+		{Code: "005", Name: "Но-шпа", Quantity: 2},
+	}
+	if !slices.Equal(result.Items, want) {
+		t.Errorf("Items = %+v, want %+v", result.Items, want)
+	}
+	wantErrors := []LineError{{Line: 6, Name: "Аспирин", Code: CodeQuantity, Value: "два"}}
+	if !slices.Equal(result.Errors, wantErrors) {
+		t.Errorf("Errors = %+v, want %+v", result.Errors, wantErrors)
+	}
+
+	if _, err := parseTable([][]string{{"Код", "Цена"}, {"1", "100"}}); err == nil {
+		t.Errorf("table without a name column is parsed")
+	}
+}
+
+func TestParseTableWithoutQuantity(t *testing.T) {
+	result, err := parseTable([][]string{{"Товар"}, {"Нурофен"}, {"Аспирин"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{{Name: "Нурофен"}, {Name: "Аспирин"}}
+	if !slices.Equal(result.Items, want) {
+		t.Errorf("Items = %+v, want %+v with unknown quantity", result.Items, want)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := map[string][]byte{
+		"semicolon": []byte("Код;Наименование;Количество\n1;Нурофен;5\n2;Аспирин;1,5\n"),
+		"tab":       []byte("Код\tНаименование\tКоличество\n1\tНурофен\t5\n2\tАспирин\t1,5\n"),
+		"comma":     []byte("Код,Наименование,Количество\n1,Нурофен,5\n2,Аспирин,\"1,5\"\n"),
+		"bom":       append([]byte{0xEF, 0xBB, 0xBF}, "Код;Наименование;Количество\n1;Нурофен;5\n2;Аспирин;1\n"...),
+	}
+	cp1251, _ := charmap.Windows1251.NewEncoder().Bytes([]byte("Код;Наименование;Количество\n1;Нурофен;5\n2;Аспирин;1\n"))
+	tests["windows-1251"] = cp1251
+
+	want := []Item{{Code: "1", Name: "Нурофен", Quantity: 5}, {Code: "2", Name: "Аспирин", Quantity: 1}}
+	for name, data := range tests {
+		result, err := Parse("stock.csv", bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !slices.Equal(result.Items, want) {
+			t.Errorf("%s: Items = %+v, want %+v", name, result.Items, want)
+		}
+	}
+}
+
+func TestParseCommerceML(t *testing.T) {
+	offers := `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация>
+  <ПакетПредложений>
+    <Предложения>
+      <Предложение><Ид>a1</Ид><Артикул>001</Артикул><Наименование>Нурофен</Наименование><Количество>5</Количество></Предложение>
+      <Предложение><Ид>a2</Ид><Наименование>Аспирин</Наименование><Склад КоличествоНаСкладе="2"/><Склад КоличествоНаСкладе="3"/></Предложение>
+      <Предложение><Ид>a3</Ид><Наименование>Парацетамол</Наименование><Количество>0</Количество></Предложение>
+      <Предложение><Ид>a4</Ид><Наименование>Но-шпа</Наименование><Количество>x</Количество></Предложение>
+      <Предложение><Ид>a5</Ид><Наименование>Цитрамон</Наименование></Предложение>
+    </Предложения>
+  </ПакетПредложений>
+</КоммерческаяИнформация>`
+	result, err := Parse("offers.xml", strings.NewReader(offers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{
+		{Code: "001", Name: "Нурофен", Quantity: 5},
+		{Code: "a2", Name: "Аспирин", Quantity: 5},
+		{Code: "a5", Name: "Цитрамон"},
+	}
+	if !slices.Equal(result.Items, want) {
+		t.Errorf("Items = %+v, want %+v", result.Items, want)
+	}
+	wantErrors := []LineError{{Name: "Но-шпа", Code: CodeQuantity, Value: "x"}}
+	if !slices.Equal(result.Errors, wantErrors) {
+		t.Errorf("Errors = %+v, want %+v", result.Errors, wantErrors)
+	}
+
+	cp1251, _ := charmap.Windows1251.NewEncoder().String(`<?xml version="1.0" encoding="windows-1251"?>
+<КоммерческаяИнформация><Каталог><Товары><Товар><Ид>1</Ид><Наименование>Нурофен</Наименование></Товар></Товары></Каталог></КоммерческаяИнформация>`)
+	result, err = Parse("import.xml", strings.NewReader(cp1251))
+	if err != nil || !slices.Equal(result.Items, []Item{{Code: "1", Name: "Нурофен"}}) {
+		t.Errorf("windows-1251 catalog = %+v, %v", result, err)
+	}
+
+	if _, err := Parse("other.xml", strings.NewReader(`<html><body>Not Found</body></html>`)); err == nil {
+		t.Errorf("an xml that is not CommerceML is parsed")
+	}
+}
+
+func TestParseXLSX(t *testing.T) {
+	data := testXLSX(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Остатки" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Наименование</t></si><si><t>Количество</t></si><si><r><t>Нуро</t></r><r><t>фен</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row><c r="B1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row><c r="B2" t="s"><v>2</v></c><c r="C2"><v>7</v></c></row>
+<row><c r="B3" t="inlineStr"><is><t>Аспирин</t></is></c><c r="C3"><v>2.5</v></c></row>
+</sheetData></worksheet>`,
+	})
+	result, err := Parse("Остатки.XLSX", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{{Name: "Нурофен", Quantity: 7}, {Name: "Аспирин", Quantity: 2}}
+	if !slices.Equal(result.Items, want) {
+		t.Errorf("Items = %+v, want %+v", result.Items, want)
+	}
+
+	if _, err := Parse("broken.xlsx", strings.NewReader("<html>error</html>")); err == nil {
+		t.Errorf("a broken xlsx is parsed")
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	if _, err := Parse("stock.pdf", strings.NewReader("")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Parse(pdf) error = %v", err)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "C12": 2, "Z3": 25, "AA1": 26, "AB12": 27} {
+		if got := columnIndex(ref); got != want {
+			t.Errorf("columnIndex(%s) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+func testXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
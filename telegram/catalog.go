@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/medicine"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const searchRefreshInterval = 10 * time.Minute
+
+func (lp *LongPoll) loadCatalog(ctx context.Context) error {
+	coll := lp.mongoClient.Database(db.Database).Collection("medicines")
+	cursor, err := coll.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	var medicines []model.Medicine
+	if err = cursor.All(ctx, &medicines); err != nil {
+		return err
+	}
+
+	lp.catalog.Store(medicine.NewCatalog(medicines))
+	log.Printf("Loaded medicine catalog: %d medicines", len(medicines))
+	return nil
+}
+
+// reloadSearch rebuilds the search index in background after a write
+func (lp *LongPoll) reloadSearch() {
+	go func() {
+		if err := lp.engine.Reload(context.Background(), lp.catalog.Load()); err != nil {
+			log.Printf("Could not rebuild search index: %s", err.Error())
+		}
+	}()
+}
+
+// refreshSearch periodically reloads the catalog and rebuilds the search index
+// so that new medicines and stock are found without restart
+func (lp *LongPoll) refreshSearch() {
+	for {
+		if err := lp.loadCatalog(context.Background()); err != nil {
+			log.Printf("Could not load medicine catalog: %s", err.Error())
+		}
+		if err := lp.engine.Reload(context.Background(), lp.catalog.Load()); err != nil {
+			log.Printf("Could not rebuild search index: %s", err.Error())
+		}
+		time.Sleep(searchRefreshInterval)
+	}
+}
@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"encoding/base64"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/sashabaranov/go-openai"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const attachmentImage = "image"
+
+// images of the dialog sent to GPT, only the latest ones are downloaded
+const maxDialogImages = 3
+
+// stands for the images left out of the dialog
+const imageOmitted = "[изображение]"
+
+// image urls of the dialog history point to telegram files until the request to GPT,
+// file links of the bot API contain the token and must not leave the server
+const telegramFileScheme = "tg-file:"
+
+// dialogAttachment is a file sent by the user in a dialog, the file is kept by Telegram
+type dialogAttachment struct {
+	Type   string `bson:"type"`
+	FileId string `bson:"file_id"`
+}
+
+func largestPhoto(msg *gotgbot.Message) *gotgbot.PhotoSize {
+	if len(msg.Photo) == 0 {
+		return nil
+	}
+	return &msg.Photo[len(msg.Photo)-1]
+}
+
+func decodeAttachments(value any) []dialogAttachment {
+	items, ok := value.(primitive.A)
+	if !ok {
+		return nil
+	}
+
+	attachments := make([]dialogAttachment, 0, len(items))
+	for _, item := range items {
+		doc, ok := item.(bson.M)
+		if !ok {
+			continue
+		}
+		fileId, _ := doc["file_id"].(string)
+		kind, _ := doc["type"].(string)
+		if fileId != "" && kind == attachmentImage {
+			attachments = append(attachments, dialogAttachment{Type: kind, FileId: fileId})
+		}
+	}
+	return attachments
+}
+
+// resolveImages replaces telegram file references of the latest images with
+// inline data urls, older images are left out so that the whole history is not
+// downloaded on every turn, an image that fails to download is skipped
+func (lp *LongPoll) resolveImages(messages []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	return resolveLatestImages(messages, maxDialogImages, lp.imageDataURL)
+}
+
+func resolveLatestImages(messages []openai.ChatCompletionMessage, n int, resolve func(fileId string) (string, error)) []openai.ChatCompletionMessage {
+	// the images are counted from the end of the dialog
+	latest := map[[2]int]bool{}
+	for i := len(messages) - 1; i >= 0 && len(latest) < n; i-- {
+		for j := len(messages[i].MultiContent) - 1; j >= 0 && len(latest) < n; j-- {
+			if telegramImage(messages[i].MultiContent[j]) != "" {
+				latest[[2]int{i, j}] = true
+			}
+		}
+	}
+
+	resolved := make([]openai.ChatCompletionMessage, len(messages))
+	cache := map[string]string{}
+	for i, msg := range messages {
+		resolved[i] = msg
+		if len(msg.MultiContent) == 0 {
+			continue
+		}
+
+		parts := make([]openai.ChatMessagePart, 0, len(msg.MultiContent))
+		for j, part := range msg.MultiContent {
+			fileId := telegramImage(part)
+			if fileId == "" {
+				parts = append(parts, part)
+				continue
+			}
+			if !latest[[2]int{i, j}] {
+				continue
+			}
+
+			url, ok := cache[fileId]
+			if !ok {
+				var err error
+				if url, err = resolve(fileId); err != nil {
+					log.Printf("Could not download dialog image %s: %s", fileId, err.Error())
+					continue
+				}
+				cache[fileId] = url
+			}
+			part.ImageURL = &openai.ChatMessageImageURL{URL: url, Detail: part.ImageURL.Detail}
+			parts = append(parts, part)
+		}
+		// a message of images only still keeps its place in the dialog
+		if len(parts) == 0 {
+			parts = append(parts, openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: imageOmitted})
+		}
+		resolved[i].MultiContent = parts
+	}
+	return resolved
+}
+
+// telegramImage returns the file id of an image part that is not downloaded yet
+func telegramImage(part openai.ChatMessagePart) string {
+	if part.ImageURL == nil || !strings.HasPrefix(part.ImageURL.URL, telegramFileScheme) {
+		return ""
+	}
+	return strings.TrimPrefix(part.ImageURL.URL, telegramFileScheme)
+}
+
+func (lp *LongPoll) imageDataURL(fileId string) (string, error) {
+	file, err := lp.bot.GetFile(fileId, &gotgbot.GetFileOpts{})
+	if err != nil {
+		return "", err
+	}
+
+	body, err := lp.download(file)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBotFileSize))
+	if err != nil {
+		return "", err
+	}
+	return "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,77 @@
+package telegram
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func imageMessage(text string, fileIds ...string) openai.ChatCompletionMessage {
+	var parts []openai.ChatMessagePart
+	if text != "" {
+		parts = append(parts, openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: text})
+	}
+	for _, fileId := range fileIds {
+		parts = append(parts, openai.ChatMessagePart{
+			Type:     openai.ChatMessagePartTypeImageURL,
+			ImageURL: &openai.ChatMessageImageURL{URL: telegramFileScheme + fileId, Detail: openai.ImageURLDetailAuto},
+		})
+	}
+	return openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, MultiContent: parts}
+}
+
+func TestResolveLatestImages(t *testing.T) {
+	messages := []openai.ChatCompletionMessage{
+		imageMessage("", "old"),
+		{Role: openai.ChatMessageRoleAssistant, Content: "Это рецепт"},
+		imageMessage("вот еще", "a", "broken"),
+		imageMessage("и это", "b", "a"),
+	}
+
+	var downloaded []string
+	resolve := func(fileId string) (string, error) {
+		downloaded = append(downloaded, fileId)
+		if fileId == "broken" {
+			return "", errors.New("404 Not Found")
+		}
+		return "data:image/jpeg;base64," + fileId, nil
+	}
+
+	resolved := resolveLatestImages(messages, 4, resolve)
+	if want := []string{"a", "broken", "b"}; !slices.Equal(downloaded, want) {
+		t.Errorf("downloaded %q, want %q", downloaded, want)
+	}
+
+	urls := func(msg openai.ChatCompletionMessage) []string {
+		var result []string
+		for _, part := range msg.MultiContent {
+			if part.ImageURL != nil {
+				result = append(result, part.ImageURL.URL)
+			} else {
+				result = append(result, part.Text)
+			}
+		}
+		return result
+	}
+	tests := []struct {
+		index int
+		want  []string
+	}{
+		{0, []string{imageOmitted}},
+		{2, []string{"вот еще", "data:image/jpeg;base64,a"}},
+		{3, []string{"и это", "data:image/jpeg;base64,b", "data:image/jpeg;base64,a"}},
+	}
+	for _, tt := range tests {
+		if got := urls(resolved[tt.index]); !slices.Equal(got, tt.want) {
+			t.Errorf("message %d = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+	if resolved[1].Content != "Это рецепт" {
+		t.Errorf("text message changed: %+v", resolved[1])
+	}
+	if got := urls(messages[0]); got[0] != telegramFileScheme+"old" {
+		t.Errorf("the history is modified: %q", got)
+	}
+}
@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/phone"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func phoneKeyboard(lang i18n.Lang) *gotgbot.ReplyKeyboardMarkup {
+	return &gotgbot.ReplyKeyboardMarkup{
+		OneTimeKeyboard: true,
+		ResizeKeyboard:  true,
+		Keyboard: [][]gotgbot.KeyboardButton{{
+			{Text: i18n.T(lang, ButtonVerifyPhone), RequestContact: true},
+		}},
+	}
+}
+
+// askPhoneVerification offers to share the Telegram contact, the listing
+// phone is confirmed when it matches the number of the account
+func (lp *LongPoll) askPhoneVerification(chatId int64) error {
+	lang := lp.lang(chatId)
+	_, err := lp.bot.SendMessage(chatId, i18n.T(lang, TextVerifyPhone), &gotgbot.SendMessageOpts{ReplyMarkup: phoneKeyboard(lang)})
+	return err
+}
+
+// isVerifiedPhone tells whether the user has shared a contact with this number
+func (lp *LongPoll) isVerifiedPhone(ctx context.Context, chatId int64, e164 string) bool {
+	user, err := lp.getUser(ctx, chatId)
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not load user: %s", chatId, err.Error())
+		return false
+	}
+	return user != nil && user.Phone != "" && user.Phone == e164
+}
+
+func (lp *LongPoll) handleContact(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	chatId := msg.Chat.Id
+	contact := msg.Contact
+
+	// a forwarded contact of somebody else proves nothing
+	if msg.From == nil || contact.UserId != msg.From.Id {
+		return lp.sendText(chatId, lp.t(chatId, TextContactNotYours))
+	}
+	e164, err := phone.Parse(contact.PhoneNumber, phone.DefaultCountry)
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not parse contact phone: %s", chatId, err.Error())
+		return lp.sendText(chatId, lp.t(chatId, TextPhoneUnsupported))
+	}
+
+	bgCtx := context.Background()
+	database := lp.mongoClient.Database(db.Database)
+	_, err = database.Collection("users").UpdateOne(bgCtx,
+		bson.M{"user_id": chatId},
+		bson.M{"$set": bson.M{"phone": e164, "phone_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := database.Collection("houses").UpdateMany(bgCtx,
+		bson.M{"owner_id": chatId, "phone": e164},
+		bson.M{
+			"$set":  bson.M{"phone_verified": true},
+			"$pull": bson.M{"flags": flagUnverifiedPhone},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] Verified phone for %d houses", chatId, res.ModifiedCount)
+
+	text := lp.t(chatId, TextPhoneVerified)
+	if res.MatchedCount == 0 {
+		text = lp.t(chatId, TextPhoneMismatch, phone.Format(e164))
+	}
+	_, err = b.SendMessage(chatId, text, &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.ReplyKeyboardRemove{RemoveKeyboard: true},
+	})
+	return err
+}
@@ -0,0 +1,289 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/medicine"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/stock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bot API does not allow downloading bigger files
+const maxBotFileSize = 20 * 1024 * 1024
+
+// how many unmatched names and errors are listed in the summary
+const stockSummaryLimit = 10
+
+func (lp *LongPoll) handleDocument(b *gotgbot.Bot, ctx *ext.Context) error {
+	chat := ctx.EffectiveMessage.Chat
+	document := ctx.EffectiveMessage.Document
+
+	// photos sent as files keep EXIF, they go through the photo pipeline
+	// only while a listing is prepared, otherwise it may be a stock file
+	image := strings.HasPrefix(document.MimeType, "image/")
+	if image {
+		preparing, err := lp.preparingListing(context.Background(), chat.Id)
+		if err != nil {
+			return err
+		}
+		if preparing {
+			return lp.addPhoto(chat.Id, document.FileId)
+		}
+	}
+
+	user, err := lp.getUser(context.Background(), chat.Id)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.CanManageAptekas() {
+		if image {
+			return lp.sendText(chat.Id, lp.t(chat.Id, TextPhotoAsFile))
+		}
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextNotPharmacist))
+	}
+
+	if document.FileSize > maxBotFileSize {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextStockFileTooBig))
+	}
+
+	aptekas, err := lp.managedAptekas(context.Background(), user)
+	if err != nil {
+		return err
+	}
+	switch len(aptekas) {
+	case 0:
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextNoAptekas))
+	case 1:
+		return lp.importStock(chat.Id, aptekas[0].ID, document)
+	}
+
+	// a chain manager chooses the branch the file belongs to, the token
+	// ties the keyboard to this file when several files are sent
+	token, err := newCode()
+	if err != nil {
+		return err
+	}
+	lp.pendingStock.Set(token, pendingStock{chatId: chat.Id, document: *document}, ttlcache.DefaultTTL)
+
+	_, err = b.SendMessage(chat.Id, lp.t(chat.Id, TextStockChooseApteka), &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: aptekasKeyboard(aptekas, 1, stockPrefix(token))},
+	})
+	return err
+}
+
+// pendingStock is a file waiting for the branch to be chosen
+type pendingStock struct {
+	chatId   int64
+	document gotgbot.Document
+}
+
+// files waiting for the branch are kept at most for this many keyboards
+const pendingStockMax = 1000
+
+// stockPrefix starts the callback data of the keyboard of one file,
+// stock_<token>_<apteka id> or stock_<token>_page_<page>
+func stockPrefix(token string) string {
+	return "stock_" + token + "_"
+}
+
+func (lp *LongPoll) handleStockCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	user, err := lp.getUser(context.Background(), chatId)
+	if err != nil {
+		return err
+	}
+
+	token, target, _ := strings.Cut(strings.TrimPrefix(query.Data, "stock_"), "_")
+	if page, ok := pageOf(query.Data, stockPrefix(token)); ok {
+		aptekas, err := lp.managedAptekas(context.Background(), user)
+		if err != nil {
+			return err
+		}
+		_, _, err = b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:      chatId,
+			MessageId:   query.Message.GetMessageId(),
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: aptekasKeyboard(aptekas, page, stockPrefix(token))},
+		})
+		return err
+	}
+
+	aptekaID, err := primitive.ObjectIDFromHex(target)
+	if err != nil {
+		return nil
+	}
+	allowed, err := lp.canManageApteka(context.Background(), user, aptekaID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return lp.sendText(chatId, lp.t(chatId, TextNoAccess))
+	}
+
+	kv, _ := lp.pendingStock.GetAndDelete(token)
+	if kv == nil || kv.Value().chatId != chatId {
+		return lp.sendText(chatId, lp.t(chatId, TextStockExpired))
+	}
+	document := kv.Value().document
+
+	_, _, _ = b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+		ChatId:      chatId,
+		MessageId:   query.Message.GetMessageId(),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{},
+	})
+	return lp.importStock(chatId, aptekaID, &document)
+}
+
+// importStock replaces the inventory of the apteka with the file contents
+func (lp *LongPoll) importStock(chatId int64, aptekaID primitive.ObjectID, document *gotgbot.Document) error {
+	lp.sendText(chatId, lp.t(chatId, TextStockProcessing))
+
+	result, err := lp.parseStockFile(document)
+	if errors.Is(err, stock.ErrUnsupportedFormat) {
+		return lp.sendText(chatId, lp.t(chatId, TextStockUnsupported))
+	}
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not parse stock file %s: %s", chatId, document.FileName, err.Error())
+		return lp.sendText(chatId, lp.t(chatId, TextStockBroken))
+	}
+
+	inventory, unmatched := buildInventory(aptekaID, result.Items, lp.catalog.Load())
+	if err := lp.replaceInventory(context.Background(), inventory); err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] Imported %d items to apteka %s", chatId, len(inventory.Items), aptekaID.Hex())
+
+	lp.reloadSearch()
+
+	return lp.sendText(chatId, stockSummary(lp.lang(chatId), len(inventory.Items), unmatched, result.Errors))
+}
+
+func (lp *LongPoll) parseStockFile(document *gotgbot.Document) (*stock.Result, error) {
+	file, err := lp.bot.GetFile(document.FileId, &gotgbot.GetFileOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := lp.download(file)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return stock.Parse(document.FileName, body)
+}
+
+// download opens the file uploaded to Telegram, an error page is never
+// returned as the file contents
+func (lp *LongPoll) download(file *gotgbot.File) (io.ReadCloser, error) {
+	resp, err := http.Get(file.URL(lp.bot, &gotgbot.RequestOpts{}))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("could not download %s: %s", file.FilePath, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// buildInventory maps stock items to the catalog, items that are not
+// confidently matched are kept by name and reported back
+func buildInventory(aptekaID primitive.ObjectID, items []stock.Item, catalog *medicine.Catalog) (*model.Inventory, []string) {
+	inventory := &model.Inventory{
+		AptekaID:  aptekaID,
+		Items:     make([]model.InventoryItem, 0, len(items)),
+		UpdatedAt: time.Now(),
+	}
+
+	var unmatched []string
+	for _, item := range items {
+		inventoryItem := model.InventoryItem{Name: item.Name, Code: item.Code, Quantity: item.Quantity}
+		if match, ok := catalog.Resolve(item.Name); ok && match.Score >= medicine.ConfidentScore {
+			inventoryItem.MedicineID = match.Medicine.ID
+		} else {
+			unmatched = append(unmatched, item.Name)
+		}
+		inventory.Items = append(inventory.Items, inventoryItem)
+	}
+	return inventory, unmatched
+}
+
+// replaceInventory swaps the whole stock of the apteka in a single document write
+// and resets the stale stock reminders
+func (lp *LongPoll) replaceInventory(ctx context.Context, inventory *model.Inventory) error {
+	database := lp.mongoClient.Database(db.Database)
+	_, err := database.Collection("inventories").ReplaceOne(ctx,
+		bson.M{"_id": inventory.AptekaID}, inventory, options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection("aptekas").UpdateOne(ctx,
+		bson.M{"_id": inventory.AptekaID},
+		bson.M{
+			"$set":   bson.M{"last_inventory_update": inventory.UpdatedAt},
+			"$unset": bson.M{"missed_reminders": "", "reminded_at": ""},
+		},
+	)
+	return err
+}
+
+func (lp *LongPoll) getUser(ctx context.Context, chatId int64) (*model.User, error) {
+	coll := lp.mongoClient.Database(db.Database).Collection("users")
+
+	var user model.User
+	err := coll.FindOne(ctx, bson.M{"user_id": chatId}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func stockSummary(lang i18n.Lang, imported int, unmatched []string, errs []stock.LineError) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, TextStockUpdated))
+	sb.WriteString("\n\n" + i18n.T(lang, TextStockImported, imported))
+
+	if len(unmatched) > 0 {
+		sb.WriteString("\n" + i18n.T(lang, TextStockUnmatched, len(unmatched)))
+		for _, name := range unmatched[:min(len(unmatched), stockSummaryLimit)] {
+			sb.WriteString("\n  • " + name)
+		}
+		if len(unmatched) > stockSummaryLimit {
+			sb.WriteString("\n  …")
+		}
+	}
+
+	if len(errs) > 0 {
+		sb.WriteString("\n" + i18n.T(lang, TextStockErrors, len(errs)))
+		for _, e := range errs[:min(len(errs), stockSummaryLimit)] {
+			sb.WriteString("\n  • " + TextStockLineError(lang, e))
+		}
+		if len(errs) > stockSummaryLimit {
+			sb.WriteString("\n  …")
+		}
+	}
+	return sb.String()
+}
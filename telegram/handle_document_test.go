@@ -0,0 +1,59 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/stock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStockKeyboardData(t *testing.T) {
+	token, err := newCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aptekas := make([]model.Apteka, aptekasPageSize+1)
+	for i := range aptekas {
+		aptekas[i] = model.Apteka{ID: primitive.NewObjectID()}
+	}
+
+	keyboard := aptekasKeyboard(aptekas, 1, stockPrefix(token))
+	for _, row := range keyboard {
+		for _, button := range row {
+			if len(button.CallbackData) > 64 {
+				t.Errorf("callback data %q is %d bytes, Telegram allows 64", button.CallbackData, len(button.CallbackData))
+			}
+		}
+	}
+
+	// the callback finds the file by the token and the branch or the page after it
+	gotToken, target, _ := strings.Cut(strings.TrimPrefix(keyboard[0][0].CallbackData, "stock_"), "_")
+	if gotToken != token || target != aptekas[0].ID.Hex() {
+		t.Errorf("apteka button gives token %q and target %q", gotToken, target)
+	}
+	arrow := keyboard[len(keyboard)-1][0].CallbackData
+	gotToken, _, _ = strings.Cut(strings.TrimPrefix(arrow, "stock_"), "_")
+	if page, ok := pageOf(arrow, stockPrefix(gotToken)); gotToken != token || !ok || page != 2 {
+		t.Errorf("arrow %q gives token %q and page %d", arrow, gotToken, page)
+	}
+}
+
+func TestTextStockLineError(t *testing.T) {
+	tests := []struct {
+		lang i18n.Lang
+		err  stock.LineError
+		want string
+	}{
+		{i18n.RU, stock.LineError{Line: 6, Name: "Аспирин", Code: stock.CodeQuantity, Value: "два"}, `строка 6: некорректное количество "два"`},
+		{i18n.EN, stock.LineError{Line: 6, Name: "Аспирин", Code: stock.CodeQuantity, Value: "два"}, `line 6: wrong quantity "два"`},
+		{i18n.EN, stock.LineError{Name: "Но-шпа", Code: stock.CodeQuantity, Value: "x"}, `Но-шпа: wrong quantity "x"`},
+	}
+	for _, tt := range tests {
+		if got := TextStockLineError(tt.lang, tt.err); got != tt.want {
+			t.Errorf("TextStockLineError(%s, %+v) = %q, want %q", tt.lang, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/phone"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// callback data is house_<action>_<house id>
+const housePrefix = "house_"
+
+const (
+	houseActionPublish = "publish"
+	houseActionEdit    = "edit"
+	houseActionCancel  = "cancel"
+)
+
+// handleWebAppHouse saves the submitted listing as a draft with the photos
+// sent before and shows the preview, the listing goes to moderation once
+// the owner publishes it
+func (lp *LongPoll) handleWebAppHouse(chat *gotgbot.Chat, house *model.House) error {
+	if kv, _ := lp.houseEdits.GetAndDelete(chat.Id); kv != nil {
+		return lp.updateListing(chat.Id, kv.Value(), house)
+	}
+
+	house.OwnerID = chat.Id
+	house.Active = false
+	house.Status = model.HouseDraft
+	house.PhoneVerified = lp.isVerifiedPhone(context.Background(), chat.Id, house.Phone)
+	house.CreatedAt = time.Now()
+	if kv, _ := lp.photoCache.GetAndDelete(chat.Id); kv != nil {
+		house.Photos = kv.Value()
+	}
+
+	coll := lp.mongoClient.Database(db.Database).Collection("houses")
+	res, err := coll.InsertOne(context.Background(), house)
+	if err != nil {
+		return err
+	}
+	house.ID = res.InsertedID.(primitive.ObjectID)
+	log.Printf("[ChatId=%d] Created draft house %s with %d photos", chat.Id, house.ID.Hex(), len(house.Photos))
+
+	return lp.sendHousePreview(chat.Id, house)
+}
+
+func (lp *LongPoll) sendHousePreview(chatId int64, house *model.House) error {
+	if err := lp.sendHousePhotos(chatId, house.Photos); err != nil {
+		log.Printf("[ChatId=%d] Could not send house photos: %s", chatId, err.Error())
+	}
+
+	lang := lp.lang(chatId)
+	id := house.ID.Hex()
+	keyboard := [][]gotgbot.InlineKeyboardButton{
+		{{Text: i18n.T(lang, ButtonPublish), CallbackData: housePrefix + houseActionPublish + "_" + id}},
+		{
+			{Text: i18n.T(lang, ButtonEdit), CallbackData: housePrefix + houseActionEdit + "_" + id},
+			{Text: i18n.T(lang, ButtonCancel), CallbackData: housePrefix + houseActionCancel + "_" + id},
+		},
+	}
+	_, err := lp.bot.SendMessage(chatId, i18n.T(lang, TextHousePreview)+"\n\n"+houseCard(lang, house), &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	if err != nil || house.PhoneVerified {
+		return err
+	}
+	return lp.askPhoneVerification(chatId)
+}
+
+// sendHousePhotos uploads the medium variants as a single album
+func (lp *LongPoll) sendHousePhotos(chatId int64, photos []model.Photo) error {
+	if len(photos) == 0 {
+		return nil
+	}
+
+	media := make([]gotgbot.InputMedia, 0, len(photos))
+	for i, photo := range photos {
+		data, err := lp.loadBlob(photo.Medium.Key)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("photo%d%s", i, path.Ext(photo.Medium.Key))
+		media = append(media, gotgbot.InputMediaPhoto{Media: gotgbot.InputFileByReader(name, bytes.NewReader(data))})
+	}
+
+	// a media group needs at least two items
+	if len(media) == 1 {
+		_, err := lp.bot.SendPhoto(chatId, media[0].GetMedia(), nil)
+		return err
+	}
+	_, err := lp.bot.SendMediaGroup(chatId, media, nil)
+	return err
+}
+
+func (lp *LongPoll) loadBlob(key string) ([]byte, error) {
+	object, err := lp.blobStore.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+func (lp *LongPoll) handleHouseCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	action, hex, _ := strings.Cut(strings.TrimPrefix(query.Data, housePrefix), "_")
+	houseID, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil
+	}
+
+	// only the owner can act on the draft, submitted listings are not touched here
+	filter := bson.M{"_id": houseID, "owner_id": chatId, "status": model.HouseDraft}
+	lang := lp.lang(chatId)
+	coll := lp.mongoClient.Database(db.Database).Collection("houses")
+	bgCtx := context.Background()
+
+	var house model.House
+	var text string
+	switch action {
+	case houseActionPublish:
+		// the status is switched first so that a double click submits once
+		err = coll.FindOneAndUpdate(bgCtx, filter,
+			bson.M{"$set": bson.M{"status": model.HousePending}},
+		).Decode(&house)
+		if err == nil {
+			err = lp.submitHouse(bgCtx, &house)
+		}
+		text = i18n.T(lang, TextHouseSubmitted) + "\n\n" + houseCard(lang, &house)
+	case houseActionEdit:
+		err = coll.FindOneAndDelete(bgCtx, filter).Decode(&house)
+		// the photos are kept for the corrected form
+		if err == nil && len(house.Photos) > 0 {
+			lp.photoCache.Set(chatId, house.Photos, ttlcache.DefaultTTL)
+		}
+		text = i18n.T(lang, TextHouseEdit)
+	case houseActionCancel:
+		err = coll.FindOneAndDelete(bgCtx, filter).Decode(&house)
+		text = i18n.T(lang, TextHouseCancelled)
+	default:
+		return nil
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return lp.sendText(chatId, i18n.T(lang, TextHouseNotFound))
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] House %s: %s", chatId, houseID.Hex(), action)
+
+	_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:    chatId,
+		MessageId: query.Message.GetMessageId(),
+	})
+	return err
+}
+
+func houseCard(lang i18n.Lang, house *model.House) string {
+	card := EmojiHouse + " " + house.City + ", " + house.Address + "\n" +
+		EmojiDoor + " " + TextRooms(lang, house.RoomCount) + "\n" +
+		EmojiMoney + " " + TextPrice(lang, house.Price) + "\n" +
+		EmojiPhone + " " + phone.Format(house.Phone)
+	if len(house.Photos) > 0 {
+		card += "\n" + EmojiCamera + " " + TextPhotos(lang, len(house.Photos))
+	}
+	return card
+}
@@ -0,0 +1,211 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// callback data is alert_off_<alert id>
+const alertPrefix = "alert_"
+
+// a user can not keep more saved searches than this, every search takes
+// one of the slots from 0 to houseAlertsMax-1
+const houseAlertsMax = 5
+
+// not more notifications than this are sent to a user per houseAlertWindow,
+// the rest of the matches are skipped
+const houseAlertLimit = 5
+const houseAlertWindow = time.Hour
+
+// saveHouseAlert subscribes the user to the /houses search
+func (lp *LongPoll) saveHouseAlert(chatId int64, q houseQuery) error {
+	ctx := context.Background()
+	coll := lp.mongoClient.Database(db.Database).Collection("house_alerts")
+	alert := model.HouseAlert{
+		ChatID:    chatId,
+		City:      strings.ToLower(q.City),
+		RoomsMin:  q.RoomsMin,
+		RoomsMax:  q.RoomsMax,
+		PriceMin:  q.PriceMin,
+		PriceMax:  q.PriceMax,
+		CreatedAt: time.Now(),
+	}
+	// the unique index on chat_id and slot rejects a taken slot, so
+	// concurrent saves can not exceed houseAlertsMax
+	for slot := range houseAlertsMax {
+		alert.Slot = slot
+		_, err := coll.InsertOne(ctx, alert)
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("[ChatId=%d] Saved house alert %+v", chatId, q)
+		return lp.sendText(chatId, lp.t(chatId, TextAlertSaved))
+	}
+	return lp.sendText(chatId, lp.t(chatId, TextTooManyAlerts, houseAlertsMax))
+}
+
+// handleAlerts lists the saved searches with unsubscribe buttons
+func (lp *LongPoll) handleAlerts(b *gotgbot.Bot, ctx *ext.Context) error {
+	chatId := ctx.EffectiveMessage.Chat.Id
+
+	cursor, err := lp.mongoClient.Database(db.Database).Collection("house_alerts").
+		Find(context.Background(), bson.M{"chat_id": chatId})
+	if err != nil {
+		return err
+	}
+	var alerts []model.HouseAlert
+	if err = cursor.All(context.Background(), &alerts); err != nil {
+		return err
+	}
+	if len(alerts) == 0 {
+		return lp.sendText(chatId, lp.t(chatId, TextNoAlerts))
+	}
+
+	lang := lp.lang(chatId)
+	var keyboard [][]gotgbot.InlineKeyboardButton
+	for _, alert := range alerts {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: "❌ " + alertTitle(lang, &alert), CallbackData: alertPrefix + "off_" + alert.ID.Hex()},
+		})
+	}
+	_, err = b.SendMessage(chatId, i18n.T(lang, TextAlerts), &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	return err
+}
+
+func (lp *LongPoll) handleAlertCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	alertID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(query.Data, alertPrefix+"off_"))
+	if err != nil {
+		return nil
+	}
+	_, err = lp.mongoClient.Database(db.Database).Collection("house_alerts").
+		DeleteOne(context.Background(), bson.M{"_id": alertID, "chat_id": chatId})
+	if err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] Deleted house alert %s", chatId, alertID.Hex())
+
+	return lp.sendText(chatId, lp.t(chatId, TextAlertDeleted))
+}
+
+// notifyHouseAlerts sends the newly published listing to the matching
+// subscribers, a listing is announced only once even if republished
+func (lp *LongPoll) notifyHouseAlerts(houseID primitive.ObjectID) {
+	ctx := context.Background()
+	database := lp.mongoClient.Database(db.Database)
+
+	var house model.House
+	err := database.Collection("houses").FindOneAndUpdate(ctx,
+		bson.M{"_id": houseID, "active": true, "alerts_sent": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"alerts_sent": true}},
+	).Decode(&house)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	}
+	if err != nil {
+		log.Printf("Could not load house %s for alerts: %s", houseID.Hex(), err.Error())
+		return
+	}
+
+	cursor, err := database.Collection("house_alerts").Find(ctx, bson.M{
+		"chat_id": bson.M{"$ne": house.OwnerID},
+		"city":    bson.M{"$in": bson.A{nil, strings.ToLower(house.City)}},
+	})
+	if err != nil {
+		log.Printf("Could not load house alerts: %s", err.Error())
+		return
+	}
+	var alerts []model.HouseAlert
+	if err = cursor.All(ctx, &alerts); err != nil {
+		log.Printf("Could not load house alerts: %s", err.Error())
+		return
+	}
+
+	notified := map[int64]bool{}
+	for _, alert := range alerts {
+		if notified[alert.ChatID] || !alert.Matches(&house) {
+			continue
+		}
+		notified[alert.ChatID] = true
+		if !lp.allowHouseAlert(alert.ChatID) {
+			log.Printf("[ChatId=%d] House alert rate limit reached, skipped %s", alert.ChatID, house.ID.Hex())
+			continue
+		}
+		if err := lp.sendHouseAlert(&alert, &house); err != nil {
+			log.Printf("[ChatId=%d] Could not send house alert: %s", alert.ChatID, err.Error())
+		}
+	}
+	log.Printf("House %s was sent to %d subscribers", house.ID.Hex(), len(notified))
+}
+
+// allowHouseAlert counts notifications of the user in a fixed window
+func (lp *LongPoll) allowHouseAlert(chatId int64) bool {
+	lp.alertMu.Lock()
+	defer lp.alertMu.Unlock()
+
+	kv := lp.alertCounts.Get(chatId)
+	if kv == nil {
+		lp.alertCounts.Set(chatId, 1, ttlcache.DefaultTTL)
+		return true
+	}
+	if kv.Value() >= houseAlertLimit {
+		return false
+	}
+	lp.alertCounts.Set(chatId, kv.Value()+1, time.Until(kv.ExpiresAt()))
+	return true
+}
+
+// sendHouseAlert notifies the subscriber in their own language
+func (lp *LongPoll) sendHouseAlert(alert *model.HouseAlert, house *model.House) error {
+	lang := lp.lang(alert.ChatID)
+	keyboard := [][]gotgbot.InlineKeyboardButton{{
+		{Text: i18n.T(lang, ButtonUnsubscribe), CallbackData: alertPrefix + "off_" + alert.ID.Hex()},
+	}}
+	if len(house.Photos) > 0 {
+		keyboard[0] = append([]gotgbot.InlineKeyboardButton{
+			{Text: EmojiCamera + " " + i18n.T(lang, ButtonPhotos), CallbackData: houseSearchPrefix + "photos_" + house.ID.Hex()},
+		}, keyboard[0]...)
+	}
+	_, err := lp.bot.SendMessage(alert.ChatID, i18n.T(lang, TextNewHouse)+"\n\n"+houseCard(lang, house), &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	return err
+}
+
+func alertTitle(lang i18n.Lang, alert *model.HouseAlert) string {
+	var parts []string
+	if alert.City != "" {
+		parts = append(parts, alert.City)
+	}
+	if alert.RoomsMin > 0 || alert.RoomsMax > 0 {
+		parts = append(parts, EmojiDoor+" "+boundsText(lang, alert.RoomsMin, alert.RoomsMax))
+	}
+	if alert.PriceMin > 0 || alert.PriceMax > 0 {
+		parts = append(parts, EmojiMoney+" "+boundsText(lang, alert.PriceMin, alert.PriceMax))
+	}
+	if len(parts) == 0 {
+		return i18n.T(lang, TextAllHouses)
+	}
+	return strings.Join(parts, ", ")
+}
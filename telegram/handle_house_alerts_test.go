@@ -0,0 +1,34 @@
+package telegram
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jellydator/ttlcache/v3"
+)
+
+func TestAllowHouseAlertConcurrent(t *testing.T) {
+	lp := &LongPoll{alertCounts: ttlcache.New(ttlcache.WithTTL[int64, int](houseAlertWindow))}
+	const chatId, listings = 1, 50
+
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	for range listings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if lp.allowHouseAlert(chatId) {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed.Load() != houseAlertLimit {
+		t.Errorf("%d alerts allowed, want %d", allowed.Load(), houseAlertLimit)
+	}
+	if !lp.allowHouseAlert(chatId + 1) {
+		t.Error("the limit of one user applies to another")
+	}
+}
@@ -0,0 +1,455 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/gorilla/mux"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const houseSearchLimit = 5
+
+// the mini-app can not ask for more listings in one page
+const houseSearchMaxLimit = 50
+
+// pages further are clamped so that the skip can not overflow
+const houseSearchMaxPage = 1000
+
+// callback data is hsearch_page_<search>_<page>, hsearch_photos_<house id>
+// or hsearch_save_<search>, where search is the key of houseSearches
+const houseSearchPrefix = "hsearch_"
+
+// searches kept for the page and save buttons, the oldest are evicted
+const houseSearchesMax = 10000
+
+const (
+	houseSortNew       = "new"
+	houseSortPrice     = "price"
+	houseSortPriceDesc = "-price"
+	houseSortRooms     = "rooms"
+)
+
+type houseQuery struct {
+	City string
+	// Text is matched against the address with the text index
+	Text     string
+	RoomsMin int
+	RoomsMax int
+	PriceMin int
+	PriceMax int
+	Sort     string
+	// Page starts from 1
+	Page  int
+	Limit int
+}
+
+type HousePayload struct {
+	ID          string         `json:"id"`
+	City        string         `json:"city"`
+	Address     string         `json:"address"`
+	Phone       string         `json:"phone"`
+	RoomCount   int            `json:"room_count"`
+	Price       int            `json:"price"`
+	Photos      []PhotoPayload `json:"photos"`
+	PublishedAt time.Time      `json:"published_at"`
+}
+
+// PhotoPayload holds paths of the photo sizes served by GET /photos/{key}
+type PhotoPayload struct {
+	Original  string `json:"original"`
+	Medium    string `json:"medium"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+type HouseSearchPayload struct {
+	Houses []HousePayload `json:"houses"`
+	Total  int64          `json:"total"`
+	Page   int            `json:"page"`
+	Pages  int            `json:"pages"`
+}
+
+func (q *houseQuery) filter() bson.M {
+	filter := bson.M{"active": true}
+	if q.City != "" {
+		filter["city"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(q.City) + "$", Options: "i"}
+	}
+	if q.Text != "" {
+		filter["$text"] = bson.M{"$search": q.Text}
+	}
+	if rooms := rangeFilter(q.RoomsMin, q.RoomsMax); rooms != nil {
+		filter["room_count"] = rooms
+	}
+	if price := rangeFilter(q.PriceMin, q.PriceMax); price != nil {
+		filter["price"] = price
+	}
+	return filter
+}
+
+func rangeFilter(from, to int) bson.M {
+	r := bson.M{}
+	if from > 0 {
+		r["$gte"] = from
+	}
+	if to > 0 {
+		r["$lte"] = to
+	}
+	if len(r) == 0 {
+		return nil
+	}
+	return r
+}
+
+func (q *houseQuery) findOptions() *options.FindOptions {
+	opts := options.Find().SetSkip(int64(q.Page-1) * int64(q.Limit)).SetLimit(int64(q.Limit))
+	switch q.Sort {
+	case houseSortPrice:
+		opts.SetSort(bson.D{{Key: "price", Value: 1}, {Key: "published_at", Value: -1}})
+	case houseSortPriceDesc:
+		opts.SetSort(bson.D{{Key: "price", Value: -1}, {Key: "published_at", Value: -1}})
+	case houseSortRooms:
+		opts.SetSort(bson.D{{Key: "room_count", Value: 1}, {Key: "published_at", Value: -1}})
+	case "":
+		// the best address matches go first when searching by text
+		if q.Text != "" {
+			score := bson.M{"$meta": "textScore"}
+			opts.SetProjection(bson.M{"score": score})
+			opts.SetSort(bson.D{{Key: "score", Value: score}, {Key: "published_at", Value: -1}})
+			break
+		}
+		fallthrough
+	default:
+		opts.SetSort(bson.D{{Key: "published_at", Value: -1}})
+	}
+	return opts
+}
+
+func (lp *LongPoll) findHouses(ctx context.Context, q *houseQuery) ([]model.House, int64, error) {
+	coll := lp.mongoClient.Database(db.Database).Collection("houses")
+	filter := q.filter()
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := coll.Find(ctx, filter, q.findOptions())
+	if err != nil {
+		return nil, 0, err
+	}
+	var houses []model.House
+	if err = cursor.All(ctx, &houses); err != nil {
+		return nil, 0, err
+	}
+	return houses, total, nil
+}
+
+// SearchHouses serves GET /houses for the listings mini-app, parameters are
+// city, q, rooms_min, rooms_max, price_min, price_max, sort, page, limit and
+// lang, the language of the errors
+func (lp *LongPoll) SearchHouses(w http.ResponseWriter, r *http.Request) {
+	q, validationErr := houseQueryFromURL(r)
+	if validationErr != nil {
+		writeJSON(w, http.StatusBadRequest, validationPayload(requestLang(r), validationErr.Fields))
+		return
+	}
+
+	houses, total, err := lp.findHouses(r.Context(), q)
+	if err != nil {
+		log.Printf("[SearchHouses] Could not search houses: %s", err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	payload := HouseSearchPayload{
+		Houses: make([]HousePayload, 0, len(houses)),
+		Total:  total,
+		Page:   q.Page,
+		Pages:  pageCount(total, q.Limit),
+	}
+	for i := range houses {
+		payload.Houses = append(payload.Houses, housePayload(&houses[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payload)
+}
+
+// GetHouse serves GET /houses/{id}, only published listings are visible
+func (lp *LongPoll) GetHouse(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "house not found", http.StatusNotFound)
+		return
+	}
+
+	var house model.House
+	err = lp.mongoClient.Database(db.Database).Collection("houses").
+		FindOne(r.Context(), bson.M{"_id": id, "active": true}).
+		Decode(&house)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "house not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[GetHouse] Could not load house %s: %s", id.Hex(), err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(housePayload(&house))
+}
+
+// houseQueryFromURL reports all wrong parameters at once
+func houseQueryFromURL(r *http.Request) (*houseQuery, *model.ValidationError) {
+	values := r.URL.Query()
+	q := &houseQuery{
+		City: strings.TrimSpace(values.Get("city")),
+		Text: strings.TrimSpace(values.Get("q")),
+		Sort: values.Get("sort"),
+	}
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"rooms_min", &q.RoomsMin},
+		{"rooms_max", &q.RoomsMax},
+		{"price_min", &q.PriceMin},
+		{"price_max", &q.PriceMax},
+		{"page", &q.Page},
+		{"limit", &q.Limit},
+	}
+	var fields []model.FieldError
+	for _, p := range ints {
+		value := values.Get(p.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			fields = append(fields, model.FieldError{Field: p.name, Code: model.CodeFormat})
+			continue
+		}
+		*p.dst = n
+	}
+	// the minimum can not exceed the maximum
+	if q.RoomsMax > 0 && q.RoomsMin > q.RoomsMax {
+		fields = append(fields, model.FieldError{Field: "rooms_min", Code: model.CodeRange, Params: []int{0, q.RoomsMax}})
+	}
+	if q.PriceMax > 0 && q.PriceMin > q.PriceMax {
+		fields = append(fields, model.FieldError{Field: "price_min", Code: model.CodeRange, Params: []int{0, q.PriceMax}})
+	}
+
+	switch q.Sort {
+	case "", houseSortNew, houseSortPrice, houseSortPriceDesc, houseSortRooms:
+	default:
+		fields = append(fields, model.FieldError{Field: "sort", Code: model.CodeFormat})
+	}
+	if len(fields) > 0 {
+		return nil, &model.ValidationError{Fields: fields}
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Limit == 0 {
+		q.Limit = houseSearchLimit
+	}
+	q.Page = min(q.Page, houseSearchMaxPage)
+	q.Limit = min(q.Limit, houseSearchMaxLimit)
+	return q, nil
+}
+
+func housePayload(house *model.House) HousePayload {
+	payload := HousePayload{
+		ID:          house.ID.Hex(),
+		City:        house.City,
+		Address:     house.Address,
+		Phone:       house.Phone,
+		RoomCount:   house.RoomCount,
+		Price:       house.Price,
+		Photos:      make([]PhotoPayload, 0, len(house.Photos)),
+		PublishedAt: house.PublishedAt,
+	}
+	for _, photo := range house.Photos {
+		payload.Photos = append(payload.Photos, PhotoPayload{
+			Original:  "/photos/" + photo.Original.Key,
+			Medium:    "/photos/" + photo.Medium.Key,
+			Thumbnail: "/photos/" + photo.Thumbnail.Key,
+		})
+	}
+	return payload
+}
+
+func pageCount(total int64, limit int) int {
+	return int((total + int64(limit) - 1) / int64(limit))
+}
+
+// handleHouses searches listings by "/houses [город] [комнаты или 2-3] [от N] [до N]"
+func (lp *LongPoll) handleHouses(b *gotgbot.Bot, ctx *ext.Context) error {
+	chatId := ctx.EffectiveMessage.Chat.Id
+
+	q := parseHouseArgs(strings.TrimPrefix(ctx.EffectiveMessage.Text, "/houses"))
+	// every search has its own key so that the buttons of an older
+	// search do not act on the latest one
+	search := primitive.NewObjectID().Hex()
+	lp.houseSearches.Set(search, *q, ttlcache.DefaultTTL)
+	return lp.sendHousePage(chatId, search, q)
+}
+
+// parseHouseArgs takes a single number or a range as the room count, numbers
+// after "от" and "до" as the price and the rest as the city
+func parseHouseArgs(args string) *houseQuery {
+	q := &houseQuery{Page: 1, Limit: houseSearchLimit}
+	var city []string
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		field := strings.ToLower(fields[i])
+		if (field == "от" || field == "до") && i+1 < len(fields) {
+			if price, err := strconv.Atoi(fields[i+1]); err == nil && price >= 0 {
+				if field == "от" {
+					q.PriceMin = price
+				} else {
+					q.PriceMax = price
+				}
+				i++
+				continue
+			}
+		}
+		if from, to, ok := parseRange(field); ok && q.RoomsMin == 0 && q.RoomsMax == 0 {
+			q.RoomsMin, q.RoomsMax = from, to
+			continue
+		}
+		city = append(city, fields[i])
+	}
+	q.City = strings.Join(city, " ")
+	return q
+}
+
+func parseRange(s string) (int, int, bool) {
+	fromText, toText, isRange := strings.Cut(s, "-")
+	from, err := strconv.Atoi(fromText)
+	if err != nil || from <= 0 {
+		return 0, 0, false
+	}
+	if !isRange {
+		return from, from, true
+	}
+	to, err := strconv.Atoi(toText)
+	if err != nil || to < from {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+func (lp *LongPoll) sendHousePage(chatId int64, search string, q *houseQuery) error {
+	houses, total, err := lp.findHouses(context.Background(), q)
+	if err != nil {
+		return err
+	}
+	lang := lp.lang(chatId)
+	// nothing is found yet, but the user can wait for it
+	if total == 0 {
+		_, err = lp.bot.SendMessage(chatId, i18n.T(lang, TextHousesNotFound), &gotgbot.SendMessageOpts{
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{{Text: i18n.T(lang, ButtonNotifyNew), CallbackData: houseSearchPrefix + "save_" + search}},
+			}},
+		})
+		return err
+	}
+
+	for i := range houses {
+		house := &houses[i]
+		opts := &gotgbot.SendMessageOpts{}
+		if len(house.Photos) > 0 {
+			opts.ReplyMarkup = gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{Text: EmojiCamera + " " + i18n.T(lang, ButtonPhotos), CallbackData: houseSearchPrefix + "photos_" + house.ID.Hex()},
+			}}}
+		}
+		if _, err := lp.bot.SendMessage(chatId, houseCard(lang, house), opts); err != nil {
+			return err
+		}
+	}
+
+	var nav []gotgbot.InlineKeyboardButton
+	if q.Page > 1 {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "◀️", CallbackData: houseSearchPrefix + "page_" + search + "_" + strconv.Itoa(q.Page-1)})
+	}
+	pages := pageCount(total, q.Limit)
+	if q.Page < pages {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "▶️", CallbackData: houseSearchPrefix + "page_" + search + "_" + strconv.Itoa(q.Page+1)})
+	}
+	keyboard := [][]gotgbot.InlineKeyboardButton{
+		{{Text: i18n.T(lang, ButtonNotifyNew), CallbackData: houseSearchPrefix + "save_" + search}},
+	}
+	if len(nav) > 0 {
+		keyboard = append([][]gotgbot.InlineKeyboardButton{nav}, keyboard...)
+	}
+	_, err = lp.bot.SendMessage(chatId, i18n.N(lang, TextHousesPage, int(total), total, q.Page, pages), &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	return err
+}
+
+func (lp *LongPoll) handleHouseSearchCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	action, value, _ := strings.Cut(strings.TrimPrefix(query.Data, houseSearchPrefix), "_")
+	switch action {
+	case "save":
+		kv := lp.houseSearches.Get(value)
+		if kv == nil {
+			return lp.sendText(chatId, lp.t(chatId, TextHouseSearchExpired))
+		}
+		return lp.saveHouseAlert(chatId, kv.Value())
+	case "page":
+		search, pageText, _ := strings.Cut(value, "_")
+		page, err := strconv.Atoi(pageText)
+		if err != nil || page < 1 {
+			return nil
+		}
+		kv := lp.houseSearches.Get(search)
+		if kv == nil {
+			return lp.sendText(chatId, lp.t(chatId, TextHouseSearchExpired))
+		}
+		q := kv.Value()
+		q.Page = min(page, houseSearchMaxPage)
+		return lp.sendHousePage(chatId, search, &q)
+	case "photos":
+		houseID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil
+		}
+		var house model.House
+		err = lp.mongoClient.Database(db.Database).Collection("houses").
+			FindOne(context.Background(), bson.M{"_id": houseID, "active": true}).
+			Decode(&house)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return lp.sendText(chatId, lp.t(chatId, TextHouseUnavailable))
+		}
+		if err != nil {
+			return err
+		}
+		return lp.sendHousePhotos(chatId, house.Photos)
+	}
+	return nil
+}
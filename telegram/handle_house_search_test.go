@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/oybek/jethouse/i18n"
+)
+
+func TestParseHouseArgs(t *testing.T) {
+	tests := []struct {
+		args string
+		want houseQuery
+	}{
+		{"", houseQuery{}},
+		{" Бишкек ", houseQuery{City: "Бишкек"}},
+		{"Бишкек 2", houseQuery{City: "Бишкек", RoomsMin: 2, RoomsMax: 2}},
+		{"2-3 Кара Балта", houseQuery{City: "Кара Балта", RoomsMin: 2, RoomsMax: 3}},
+		{"Ош от 10000 до 30000", houseQuery{City: "Ош", PriceMin: 10000, PriceMax: 30000}},
+		{"ДО 500 Ош", houseQuery{City: "Ош", PriceMax: 500}},
+		// only the first number is the room count
+		{"2 3", houseQuery{City: "3", RoomsMin: 2, RoomsMax: 2}},
+		// words that are not numbers stay in the city
+		{"от центра", houseQuery{City: "от центра"}},
+		{"Ош до", houseQuery{City: "Ош до"}},
+		{"Ош до -5", houseQuery{City: "Ош до -5"}},
+		{"3-2 Ош", houseQuery{City: "3-2 Ош"}},
+		{"0 Ош", houseQuery{City: "0 Ош"}},
+	}
+	for _, tt := range tests {
+		got := parseHouseArgs(tt.args)
+		tt.want.Page, tt.want.Limit = 1, houseSearchLimit
+		if *got != tt.want {
+			t.Errorf("parseHouseArgs(%q) = %+v, want %+v", tt.args, *got, tt.want)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		s        string
+		from, to int
+		ok       bool
+	}{
+		{"1", 1, 1, true},
+		{"2-4", 2, 4, true},
+		{"3-3", 3, 3, true},
+		{"0", 0, 0, false},
+		{"-1", 0, 0, false},
+		{"4-2", 0, 0, false},
+		{"2-", 0, 0, false},
+		{"2-x", 0, 0, false},
+		{"x", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		from, to, ok := parseRange(tt.s)
+		if from != tt.from || to != tt.to || ok != tt.ok {
+			t.Errorf("parseRange(%q) = %d, %d, %t, want %d, %d, %t", tt.s, from, to, ok, tt.from, tt.to, tt.ok)
+		}
+	}
+}
+
+func TestHouseQueryFromURL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  houseQuery
+	}{
+		{"", houseQuery{Page: 1, Limit: houseSearchLimit}},
+		{"city=+Ош+&q=Ленина&sort=-price&rooms_min=1&rooms_max=2&price_min=5&price_max=9&page=3&limit=20",
+			houseQuery{City: "Ош", Text: "Ленина", Sort: houseSortPriceDesc, RoomsMin: 1, RoomsMax: 2, PriceMin: 5, PriceMax: 9, Page: 3, Limit: 20}},
+		{"page=9223372036854775807&limit=9223372036854775807", houseQuery{Page: houseSearchMaxPage, Limit: houseSearchMaxLimit}},
+		{"rooms_max=0&rooms_min=3", houseQuery{RoomsMin: 3, Page: 1, Limit: houseSearchLimit}},
+	}
+	for _, tt := range tests {
+		got, err := houseQueryFromURL(httptest.NewRequest("GET", "/houses?"+tt.query, nil))
+		if err != nil {
+			t.Errorf("houseQueryFromURL(%q) error: %v", tt.query, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("houseQueryFromURL(%q) = %+v, want %+v", tt.query, *got, tt.want)
+		}
+		if skip := *got.findOptions().Skip; skip < 0 {
+			t.Errorf("houseQueryFromURL(%q) skips %d listings", tt.query, skip)
+		}
+	}
+}
+
+func TestHouseQueryFromURLErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		fields []string
+	}{
+		{"page=-1", []string{"page"}},
+		{"limit=x", []string{"limit"}},
+		{"page=99999999999999999999", []string{"page"}},
+		{"rooms_min=3&rooms_max=2&price_min=9&price_max=5", []string{"rooms_min", "price_min"}},
+		{"sort=cheap&price_max=-5", []string{"price_max", "sort"}},
+	}
+	for _, tt := range tests {
+		q, err := houseQueryFromURL(httptest.NewRequest("GET", "/houses?"+tt.query, nil))
+		if err == nil {
+			t.Errorf("houseQueryFromURL(%q) = %+v, want an error", tt.query, *q)
+			continue
+		}
+		var fields []string
+		for _, f := range err.Fields {
+			fields = append(fields, f.Field)
+		}
+		if !slices.Equal(fields, tt.fields) {
+			t.Errorf("houseQueryFromURL(%q) fields = %q, want %q", tt.query, fields, tt.fields)
+		}
+	}
+}
+
+func TestTextPrice(t *testing.T) {
+	tests := []struct {
+		lang  i18n.Lang
+		price int
+		want  string
+	}{
+		{i18n.RU, 0, "Цена договорная"},
+		{i18n.RU, 7, "7 сом"},
+		{i18n.RU, 999, "999 сом"},
+		{i18n.RU, 1000, "1 000 сом"},
+		{i18n.RU, 25000, "25 000 сом"},
+		{i18n.RU, 1234567, "1 234 567 сом"},
+		{i18n.EN, 150000, "150 000 som"},
+		{i18n.EN, 0, "Price negotiable"},
+	}
+	for _, tt := range tests {
+		if got := TextPrice(tt.lang, tt.price); got != tt.want {
+			t.Errorf("TextPrice(%s, %d) = %q, want %q", tt.lang, tt.price, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package telegram
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// callback data is lang_<code>
+const languagePrefix = "lang_"
+
+// languageHandler runs before the other handlers and remembers the language
+// of the user, it never stops the update
+type languageHandler struct {
+	lp *LongPoll
+}
+
+func (h languageHandler) CheckUpdate(b *gotgbot.Bot, ctx *ext.Context) bool {
+	return ctx.EffectiveUser != nil
+}
+
+func (h languageHandler) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context) error {
+	h.lp.rememberLanguage(ctx.EffectiveUser)
+	return ext.ContinueGroups
+}
+
+func (h languageHandler) Name() string {
+	return "language"
+}
+
+// rememberLanguage caches the language of the user, the language of the
+// Telegram client is saved so that notifications sent later use it too
+func (lp *LongPoll) rememberLanguage(from *gotgbot.User) {
+	if lp.languages.Has(from.Id) {
+		return
+	}
+	user, err := lp.getUser(context.Background(), from.Id)
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not load user: %s", from.Id, err.Error())
+		return
+	}
+
+	lang := i18n.Match(from.LanguageCode)
+	if user != nil && user.Language != "" {
+		lang = i18n.Match(user.Language)
+	}
+	lp.languages.Set(from.Id, lang, ttlcache.DefaultTTL)
+
+	if user == nil || from.LanguageCode == "" || user.LanguageCode == from.LanguageCode {
+		return
+	}
+	_, err = lp.mongoClient.Database(db.Database).Collection("users").UpdateOne(context.Background(),
+		bson.M{"user_id": from.Id},
+		bson.M{"$set": bson.M{"language_code": from.LanguageCode}},
+	)
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not save language code: %s", from.Id, err.Error())
+	}
+}
+
+// lang is the language of the chat, chats unknown to the bot like the
+// moderators group get the default one
+func (lp *LongPoll) lang(chatId int64) i18n.Lang {
+	if kv := lp.languages.Get(chatId); kv != nil {
+		return kv.Value()
+	}
+	user, err := lp.getUser(context.Background(), chatId)
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not load user: %s", chatId, err.Error())
+		return i18n.Default
+	}
+
+	lang := i18n.Default
+	switch {
+	case user == nil:
+	case user.Language != "":
+		lang = i18n.Match(user.Language)
+	case user.LanguageCode != "":
+		lang = i18n.Match(user.LanguageCode)
+	}
+	lp.languages.Set(chatId, lang, ttlcache.DefaultTTL)
+	return lang
+}
+
+// t is the text in the language of the chat
+func (lp *LongPoll) t(chatId int64, key i18n.Key, args ...any) string {
+	return i18n.T(lp.lang(chatId), key, args...)
+}
+
+func (lp *LongPoll) handleLanguage(b *gotgbot.Bot, ctx *ext.Context) error {
+	chatId := ctx.EffectiveMessage.Chat.Id
+
+	var keyboard [][]gotgbot.InlineKeyboardButton
+	for _, lang := range i18n.Langs {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: i18n.Names[lang], CallbackData: languagePrefix + string(lang)},
+		})
+	}
+	_, err := b.SendMessage(chatId, lp.t(chatId, TextLanguageChoose), &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	return err
+}
+
+// handleLanguageCallback saves the chosen language, it takes precedence
+// over the language of the Telegram client
+func (lp *LongPoll) handleLanguageCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	lang, ok := i18n.Parse(strings.TrimPrefix(query.Data, languagePrefix))
+	if !ok {
+		return nil
+	}
+	_, err := lp.mongoClient.Database(db.Database).Collection("users").UpdateOne(context.Background(),
+		bson.M{"user_id": chatId},
+		bson.M{"$set": bson.M{"language": lang}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+	lp.languages.Set(chatId, lang, ttlcache.DefaultTTL)
+	log.Printf("[ChatId=%d] Changed language to %s", chatId, lang)
+
+	_, _, err = b.EditMessageText(i18n.T(lang, TextLanguageChanged), &gotgbot.EditMessageTextOpts{
+		ChatId:    chatId,
+		MessageId: query.Message.GetMessageId(),
+	})
+	return err
+}
+
+// botCommands are the menu commands described in the language
+func botCommands(lang i18n.Lang) []gotgbot.BotCommand {
+	commands := []string{"create_apteka", "location", "my_aptekas", "invite", "houses", "my_listings", "alerts", "language"}
+	result := make([]gotgbot.BotCommand, 0, len(commands))
+	for _, command := range commands {
+		result = append(result, gotgbot.BotCommand{
+			Command:     command,
+			Description: i18n.T(lang, i18n.Key("cmd_"+command)),
+		})
+	}
+	return result
+}
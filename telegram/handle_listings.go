@@ -0,0 +1,298 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/phone"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// callback data is listing_<action>_<house id>
+const listingPrefix = "listing_"
+
+const (
+	listingActionDeactivate    = "off"
+	listingActionActivate      = "on"
+	listingActionSubmit        = "submit"
+	listingActionEdit          = "edit"
+	listingActionPhotos        = "photos"
+	listingActionPhotosDone    = "photosdone"
+	listingActionDelete        = "delete"
+	listingActionDeleteConfirm = "deleteyes"
+	listingActionDeleteCancel  = "deleteno"
+)
+
+// listingPhotos collects the photos replacing the ones of a listing
+type listingPhotos struct {
+	houseID primitive.ObjectID
+	photos  []model.Photo
+}
+
+func (lp *LongPoll) handleMyListings(b *gotgbot.Bot, ctx *ext.Context) error {
+	chatId := ctx.EffectiveMessage.Chat.Id
+
+	cursor, err := lp.mongoClient.Database(db.Database).Collection("houses").Find(context.Background(),
+		bson.M{"owner_id": chatId},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return err
+	}
+	var houses []model.House
+	if err = cursor.All(context.Background(), &houses); err != nil {
+		return err
+	}
+	if len(houses) == 0 {
+		return lp.sendText(chatId, lp.t(chatId, TextNoListings))
+	}
+
+	lang := lp.lang(chatId)
+	for i := range houses {
+		_, err := b.SendMessage(chatId, listingCard(lang, &houses[i]), &gotgbot.SendMessageOpts{
+			ReplyMarkup: lp.listingKeyboard(lang, &houses[i]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listingCard(lang i18n.Lang, house *model.House) string {
+	return houseCard(lang, house) + "\n\n" + TextListingStatus(lang, house)
+}
+
+func (lp *LongPoll) listingKeyboard(lang i18n.Lang, house *model.House) gotgbot.InlineKeyboardMarkup {
+	return listingKeyboard(lang, house, lp.houseWebAppUrl != "")
+}
+
+// listingKeyboard has the edit button only if the web app is configured
+func listingKeyboard(lang i18n.Lang, house *model.House, editable bool) gotgbot.InlineKeyboardMarkup {
+	id := house.ID.Hex()
+	var keyboard [][]gotgbot.InlineKeyboardButton
+	switch {
+	case house.Status == model.HousePending:
+	case house.Status == model.HouseApproved && house.Active:
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: i18n.T(lang, ButtonUnpublish), CallbackData: listingPrefix + listingActionDeactivate + "_" + id},
+		})
+	case house.Status == model.HouseApproved:
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: i18n.T(lang, ButtonPublish), CallbackData: listingPrefix + listingActionActivate + "_" + id},
+		})
+	default:
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: i18n.T(lang, ButtonSubmit), CallbackData: listingPrefix + listingActionSubmit + "_" + id},
+		})
+	}
+	var edit []gotgbot.InlineKeyboardButton
+	if editable {
+		edit = append(edit, gotgbot.InlineKeyboardButton{Text: i18n.T(lang, ButtonEdit), CallbackData: listingPrefix + listingActionEdit + "_" + id})
+	}
+	edit = append(edit, gotgbot.InlineKeyboardButton{Text: i18n.T(lang, ButtonReplacePhotos), CallbackData: listingPrefix + listingActionPhotos + "_" + id})
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: append(keyboard,
+		edit,
+		[]gotgbot.InlineKeyboardButton{{Text: i18n.T(lang, ButtonDelete), CallbackData: listingPrefix + listingActionDelete + "_" + id}},
+	)}
+}
+
+// handleListingCallback applies the owner action, every query is filtered
+// by owner_id so that nobody else can change the listing
+func (lp *LongPoll) handleListingCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	action, hex, _ := strings.Cut(strings.TrimPrefix(query.Data, listingPrefix), "_")
+	houseID, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil
+	}
+
+	bgCtx := context.Background()
+	coll := lp.mongoClient.Database(db.Database).Collection("houses")
+	filter := bson.M{"_id": houseID, "owner_id": chatId}
+	messageId := query.Message.GetMessageId()
+	lang := lp.lang(chatId)
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var house model.House
+	switch action {
+	case listingActionDeactivate:
+		err = coll.FindOneAndUpdate(bgCtx, filter, bson.M{"$set": bson.M{"active": false}}, after).Decode(&house)
+	case listingActionActivate:
+		// only approved listings go back without moderation
+		filter["status"] = model.HouseApproved
+		err = coll.FindOneAndUpdate(bgCtx, filter,
+			bson.M{"$set": bson.M{"active": true, "published_at": time.Now()}}, after,
+		).Decode(&house)
+	case listingActionSubmit:
+		filter["status"] = bson.M{"$nin": bson.A{model.HousePending, model.HouseApproved}}
+		err = coll.FindOneAndUpdate(bgCtx, filter, bson.M{"$set": bson.M{"status": model.HousePending}}, after).Decode(&house)
+		if err == nil {
+			err = lp.submitHouse(bgCtx, &house)
+		}
+	case listingActionEdit:
+		// the button may be left on a card sent before the web app was removed
+		if lp.houseWebAppUrl == "" {
+			return nil
+		}
+		if err = coll.FindOne(bgCtx, filter).Decode(&house); err == nil {
+			return lp.startListingEdit(chatId, &house)
+		}
+	case listingActionPhotos:
+		if err = coll.FindOne(bgCtx, filter).Decode(&house); err == nil {
+			// the photos sent from now on replace the current ones
+			lp.listingPhotos.Set(chatId, listingPhotos{houseID: houseID}, ttlcache.DefaultTTL)
+			_, err = b.SendMessage(chatId, i18n.T(lang, TextListingSendPhotos), &gotgbot.SendMessageOpts{
+				ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+					{Text: i18n.T(lang, ButtonDone), CallbackData: listingPrefix + listingActionPhotosDone + "_" + hex},
+				}}},
+			})
+			return err
+		}
+	case listingActionPhotosDone:
+		kv := lp.listingPhotos.Get(chatId)
+		// the button may be left on a listing whose photos are no longer replaced
+		if kv == nil || kv.Value().houseID != houseID || len(kv.Value().photos) == 0 {
+			return lp.sendText(chatId, i18n.T(lang, TextListingNoPhotos))
+		}
+		err = coll.FindOneAndUpdate(bgCtx, filter, bson.M{"$set": bson.M{"photos": kv.Value().photos}}, after).Decode(&house)
+		// new photos are checked by moderators as well
+		if err == nil && house.Status != model.HouseDraft {
+			err = lp.submitHouse(bgCtx, &house)
+		}
+		if err == nil {
+			lp.listingPhotos.Delete(chatId)
+			_, _, _ = b.EditMessageText(i18n.T(lang, TextListingPhotosReplaced), &gotgbot.EditMessageTextOpts{
+				ChatId:    chatId,
+				MessageId: messageId,
+			})
+			log.Printf("[ChatId=%d] Replaced photos of house %s", chatId, hex)
+			return nil
+		}
+	case listingActionDelete:
+		_, _, err = b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:    chatId,
+			MessageId: messageId,
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{Text: i18n.T(lang, ButtonDeleteConfirm), CallbackData: listingPrefix + listingActionDeleteConfirm + "_" + hex},
+				{Text: i18n.T(lang, ButtonNo), CallbackData: listingPrefix + listingActionDeleteCancel + "_" + hex},
+			}}},
+		})
+		return err
+	case listingActionDeleteCancel:
+		err = coll.FindOne(bgCtx, filter).Decode(&house)
+	case listingActionDeleteConfirm:
+		res, err := coll.DeleteOne(bgCtx, filter)
+		if err != nil {
+			return err
+		}
+		if res.DeletedCount == 0 {
+			return lp.sendText(chatId, lp.t(chatId, TextHouseUnavailable))
+		}
+		log.Printf("[ChatId=%d] Deleted house %s", chatId, hex)
+		_, _, err = b.EditMessageText(i18n.T(lang, TextHouseCancelled), &gotgbot.EditMessageTextOpts{
+			ChatId:    chatId,
+			MessageId: messageId,
+		})
+		return err
+	default:
+		return nil
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return lp.sendText(chatId, lp.t(chatId, TextHouseUnavailable))
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] House %s: %s", chatId, hex, action)
+
+	_, _, err = b.EditMessageText(listingCard(lang, &house), &gotgbot.EditMessageTextOpts{
+		ChatId:      chatId,
+		MessageId:   messageId,
+		ReplyMarkup: lp.listingKeyboard(lang, &house),
+	})
+	return err
+}
+
+// startListingEdit opens the web app filled with the listing, the next
+// submitted form updates this listing instead of creating a new one
+func (lp *LongPoll) startListingEdit(chatId int64, house *model.House) error {
+	lp.houseEdits.Set(chatId, house.ID, ttlcache.DefaultTTL)
+
+	values := url.Values{}
+	values.Set("city", house.City)
+	values.Set("address", house.Address)
+	values.Set("phone", phone.Format(house.Phone))
+	values.Set("room_count", strconv.Itoa(house.RoomCount))
+	values.Set("price", strconv.Itoa(house.Price))
+
+	lang := lp.lang(chatId)
+	keyboard := &gotgbot.ReplyKeyboardMarkup{
+		OneTimeKeyboard: true,
+		ResizeKeyboard:  true,
+		Keyboard: [][]gotgbot.KeyboardButton{{
+			{Text: i18n.T(lang, ButtonEditListing), WebApp: &gotgbot.WebAppInfo{Url: lp.houseWebAppUrl + "?" + values.Encode()}},
+		}},
+	}
+	_, err := lp.bot.SendMessage(chatId, i18n.T(lang, TextListingEdit), &gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+	return err
+}
+
+// updateListing saves the edited form fields, a submitted listing is
+// moderated again because the phone or the address could change
+func (lp *LongPoll) updateListing(chatId int64, houseID primitive.ObjectID, edited *model.House) error {
+	// a changed number has to be confirmed again
+	verified := lp.isVerifiedPhone(context.Background(), chatId, edited.Phone)
+
+	var house model.House
+	err := lp.mongoClient.Database(db.Database).Collection("houses").FindOneAndUpdate(context.Background(),
+		bson.M{"_id": houseID, "owner_id": chatId},
+		bson.M{"$set": bson.M{
+			"city":           edited.City,
+			"address":        edited.Address,
+			"phone":          edited.Phone,
+			"phone_verified": verified,
+			"room_count":     edited.RoomCount,
+			"price":          edited.Price,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&house)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return lp.sendText(chatId, lp.t(chatId, TextHouseUnavailable))
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] Edited house %s", chatId, houseID.Hex())
+	if house.Status != model.HouseDraft {
+		if err := lp.submitHouse(context.Background(), &house); err != nil {
+			return err
+		}
+	}
+
+	lang := lp.lang(chatId)
+	_, err = lp.bot.SendMessage(chatId, i18n.T(lang, TextListingUpdated)+"\n\n"+listingCard(lang, &house), &gotgbot.SendMessageOpts{
+		ReplyMarkup: lp.listingKeyboard(lang, &house),
+	})
+	if err != nil || house.PhoneVerified {
+		return err
+	}
+	return lp.askPhoneVerification(chatId)
+}
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// search results are sent again with the new location if the search was this recent
+const researchWindow = 30 * time.Minute
+
+func locationKeyboard(lang i18n.Lang) *gotgbot.ReplyKeyboardMarkup {
+	return &gotgbot.ReplyKeyboardMarkup{
+		OneTimeKeyboard: true,
+		ResizeKeyboard:  true,
+		Keyboard: [][]gotgbot.KeyboardButton{
+			{
+				{Text: i18n.T(lang, ButtonSendLocation), RequestLocation: true},
+			},
+		},
+	}
+}
+
+func (lp *LongPoll) handleLocationCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	chatId := ctx.EffectiveMessage.Chat.Id
+	lang := lp.lang(chatId)
+	_, err := b.SendMessage(chatId, i18n.T(lang, TextAskLocation), &gotgbot.SendMessageOpts{
+		ReplyMarkup: locationKeyboard(lang),
+	})
+	return err
+}
+
+// handleLocation saves the location of the user and repeats the last search
+// so that aptekas are ranked by distance
+func (lp *LongPoll) handleLocation(b *gotgbot.Bot, ctx *ext.Context) error {
+	chat := ctx.EffectiveMessage.Chat
+	location := ctx.EffectiveMessage.Location
+	point := model.NewPoint(location.Latitude, location.Longitude)
+	bgCtx := context.Background()
+
+	database := lp.mongoClient.Database(db.Database)
+	_, err := database.Collection("users").UpdateOne(bgCtx,
+		bson.M{"user_id": chat.Id},
+		bson.M{"$set": bson.M{"location": point, "location_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] Saved location %f,%f", chat.Id, point.Lat(), point.Lon())
+
+	_, err = b.SendMessage(chat.Id, lp.t(chat.Id, TextLocationSaved), &gotgbot.SendMessageOpts{
+		ReplyMarkup: &gotgbot.ReplyKeyboardRemove{RemoveKeyboard: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	var request model.SearchRequest
+	err = database.Collection("search_requests").FindOne(bgCtx,
+		bson.M{"chat_id": chat.Id, "created_at": bson.M{"$gt": time.Now().Add(-researchWindow)}},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&request)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	request.Location = point
+	_, err = database.Collection("search_requests").UpdateOne(bgCtx,
+		bson.M{"_id": request.ID},
+		bson.M{"$set": bson.M{"location": point}},
+	)
+	if err != nil {
+		return err
+	}
+	return lp.sendSearchResults(&request)
+}
+
+// mapURL links the apteka on 2GIS by coordinates or by address
+func mapURL(apteka *model.Apteka) string {
+	if apteka.Location != nil {
+		return fmt.Sprintf("https://2gis.kg/geo/%.6f,%.6f", apteka.Location.Lon(), apteka.Location.Lat())
+	}
+	return "https://2gis.kg/search/" + url.PathEscape(apteka.Address)
+}
+
+// formatDistance renders meters as "350 м" or "1,2 км", only English
+// keeps the decimal point
+func formatDistance(lang i18n.Lang, meters int) string {
+	if meters < 1000 {
+		return i18n.T(lang, TextDistanceM, meters)
+	}
+	km := fmt.Sprintf("%.1f", float64(meters)/1000)
+	if lang != i18n.EN {
+		km = strings.Replace(km, ".", ",", 1)
+	}
+	return i18n.T(lang, TextDistanceKm, km)
+}
@@ -0,0 +1,502 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const invitePayloadPrefix = "inv_"
+
+// aptekas shown on one page of a keyboard, an admin manages all of them
+const aptekasPageSize = 10
+
+// apteka cards shown in one /my_aptekas message, a message holds at most
+// 4096 characters
+const aptekaCardsPageSize = 5
+
+// callback data of the /my_aptekas arrows is myapt_page_<page>
+const myAptekasPrefix = "myapt_"
+
+// pageBounds clamps the page of n items shown by size on a page and returns
+// the items of the page as [from, to)
+func pageBounds(n, size, page int) (clamped, pages, from, to int) {
+	pages = (n + size - 1) / size
+	clamped = max(1, min(page, pages))
+	return clamped, pages, (clamped - 1) * size, min(clamped*size, n)
+}
+
+// pageNav are the arrows to the neighbour pages, they send <prefix>page_<page>
+func pageNav(page, pages int, prefix string) []gotgbot.InlineKeyboardButton {
+	var nav []gotgbot.InlineKeyboardButton
+	if page > 1 {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "◀️", CallbackData: prefix + "page_" + strconv.Itoa(page-1)})
+	}
+	if page < pages {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "▶️", CallbackData: prefix + "page_" + strconv.Itoa(page+1)})
+	}
+	return nav
+}
+
+// aptekasKeyboard shows a page of the branches, the buttons send
+// <prefix><apteka id> and the arrows <prefix>page_<page>, pages start from 1
+func aptekasKeyboard(aptekas []model.Apteka, page int, prefix string) [][]gotgbot.InlineKeyboardButton {
+	page, pages, from, to := pageBounds(len(aptekas), aptekasPageSize, page)
+
+	var keyboard [][]gotgbot.InlineKeyboardButton
+	for _, apteka := range aptekas[from:to] {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: apteka.Name + ", " + apteka.Address, CallbackData: prefix + apteka.ID.Hex()},
+		})
+	}
+	if nav := pageNav(page, pages, prefix); len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+	return keyboard
+}
+
+// pageOf parses the page of the keyboard arrows from the callback data
+func pageOf(data, prefix string) (int, bool) {
+	value, ok := strings.CutPrefix(data, prefix+"page_")
+	if !ok {
+		return 0, false
+	}
+	page, err := strconv.Atoi(value)
+	return page, err == nil && page > 0
+}
+
+// managedAptekas returns the branches the user works with: own apteka
+// for a pharmacist, all branches of the chain for a manager and every
+// apteka for an admin
+func (lp *LongPoll) managedAptekas(ctx context.Context, user *model.User) ([]model.Apteka, error) {
+	var filter bson.M
+	switch {
+	case user == nil:
+		return nil, nil
+	case user.IsAdmin():
+		filter = bson.M{}
+	case user.IsChainManager():
+		filter = bson.M{"chain_id": user.ChainId}
+	case user.IsPharmacist():
+		filter = bson.M{"_id": user.AptekaId}
+	default:
+		return nil, nil
+	}
+
+	coll := lp.mongoClient.Database(db.Database).Collection("aptekas")
+	cursor, err := coll.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var aptekas []model.Apteka
+	if err = cursor.All(ctx, &aptekas); err != nil {
+		return nil, err
+	}
+	return aptekas, nil
+}
+
+func (lp *LongPoll) getApteka(ctx context.Context, id primitive.ObjectID) (*model.Apteka, error) {
+	coll := lp.mongoClient.Database(db.Database).Collection("aptekas")
+
+	var apteka model.Apteka
+	if err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&apteka); err != nil {
+		return nil, err
+	}
+	return &apteka, nil
+}
+
+// canManageApteka checks the access of the user to the branch on the server side,
+// callback data can not be trusted
+func (lp *LongPoll) canManageApteka(ctx context.Context, user *model.User, aptekaID primitive.ObjectID) (bool, error) {
+	switch {
+	case user == nil:
+		return false, nil
+	case user.IsAdmin():
+		return true, nil
+	case user.IsPharmacist():
+		return user.AptekaId == aptekaID, nil
+	case user.IsChainManager():
+		apteka, err := lp.getApteka(ctx, aptekaID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return apteka.ChainID == user.ChainId, nil
+	}
+	return false, nil
+}
+
+// handleInvite creates a pharmacist invite for the own branch or lets a
+// chain manager choose the branch or invite another manager
+func (lp *LongPoll) handleInvite(b *gotgbot.Bot, ctx *ext.Context) error {
+	chat := ctx.EffectiveMessage.Chat
+
+	user, err := lp.getUser(context.Background(), chat.Id)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.CanManageAptekas() {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextNotPharmacist))
+	}
+
+	if user.IsPharmacist() {
+		return lp.sendInvite(chat.Id, &model.Invite{Role: model.RolePharmacist, AptekaID: user.AptekaId})
+	}
+
+	keyboard, err := lp.inviteKeyboard(user, 1)
+	if err != nil {
+		return err
+	}
+	if len(keyboard) == 0 {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextNoAptekas))
+	}
+
+	_, err = b.SendMessage(chat.Id, lp.t(chat.Id, TextInviteWho), &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	return err
+}
+
+func (lp *LongPoll) inviteKeyboard(user *model.User, page int) ([][]gotgbot.InlineKeyboardButton, error) {
+	aptekas, err := lp.managedAptekas(context.Background(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	keyboard := aptekasKeyboard(aptekas, page, "invite_")
+	if user.IsChainManager() {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: lp.t(user.ChatId, ButtonInviteManager), CallbackData: "invite_manager"},
+		})
+	}
+	return keyboard, nil
+}
+
+func (lp *LongPoll) handleInviteCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	user, err := lp.getUser(context.Background(), chatId)
+	if err != nil {
+		return err
+	}
+
+	if page, ok := pageOf(query.Data, "invite_"); ok {
+		if user == nil || !user.CanManageAptekas() {
+			return nil
+		}
+		keyboard, err := lp.inviteKeyboard(user, page)
+		if err != nil {
+			return err
+		}
+		_, _, err = b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:      chatId,
+			MessageId:   query.Message.GetMessageId(),
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		})
+		return err
+	}
+
+	target := strings.TrimPrefix(query.Data, "invite_")
+	if target == "manager" {
+		if user == nil || !user.IsChainManager() {
+			return lp.sendText(chatId, lp.t(chatId, TextNoAccess))
+		}
+		return lp.sendInvite(chatId, &model.Invite{Role: model.RoleChainManager, ChainID: user.ChainId})
+	}
+
+	aptekaID, err := primitive.ObjectIDFromHex(target)
+	if err != nil {
+		return nil
+	}
+	allowed, err := lp.canManageApteka(context.Background(), user, aptekaID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return lp.sendText(chatId, lp.t(chatId, TextNoAccess))
+	}
+	return lp.sendInvite(chatId, &model.Invite{Role: model.RolePharmacist, AptekaID: aptekaID})
+}
+
+// handleCreateChain lets an admin create a pharmacy chain: /create_chain Name
+func (lp *LongPoll) handleCreateChain(b *gotgbot.Bot, ctx *ext.Context) error {
+	chat := ctx.EffectiveMessage.Chat
+
+	user, err := lp.getUser(context.Background(), chat.Id)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsAdmin() {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextNoAccess))
+	}
+
+	name := strings.TrimSpace(strings.TrimPrefix(ctx.EffectiveMessage.Text, "/create_chain"))
+	if name == "" {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextCreateChainUsage))
+	}
+
+	chain := model.Chain{Name: name, CreatedAt: time.Now()}
+	res, err := lp.mongoClient.Database(db.Database).Collection("chains").InsertOne(context.Background(), chain)
+	if err != nil {
+		return err
+	}
+
+	return lp.sendInvite(chat.Id, &model.Invite{
+		Role:    model.RoleChainManager,
+		ChainID: res.InsertedID.(primitive.ObjectID),
+	})
+}
+
+func (lp *LongPoll) sendInvite(chatId int64, invite *model.Invite) error {
+	code, err := newCode()
+	if err != nil {
+		return err
+	}
+	invite.Code = code
+	invite.CreatedBy = chatId
+	invite.CreatedAt = time.Now()
+
+	coll := lp.mongoClient.Database(db.Database).Collection("invites")
+	if _, err := coll.InsertOne(context.Background(), invite); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%s", lp.bot.User.Username, invitePayloadPrefix, code)
+	return lp.sendText(chatId, lp.t(chatId, TextInviteCreated)+"\n\n"+link)
+}
+
+// newCode is a random lowercase base32 code, it fits in links and callback data
+func newCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)), nil
+}
+
+// handleStartInvite accepts the invite from the deep link /start inv_<code>
+func (lp *LongPoll) handleStartInvite(b *gotgbot.Bot, ctx *ext.Context) error {
+	chat := ctx.EffectiveMessage.Chat
+	payload := strings.TrimSpace(strings.TrimPrefix(ctx.EffectiveMessage.Text, "/start"))
+	code := strings.TrimPrefix(payload, invitePayloadPrefix)
+
+	bgCtx := context.Background()
+	database := lp.mongoClient.Database(db.Database)
+
+	var invite model.Invite
+	err := database.Collection("invites").FindOne(bgCtx, bson.M{"_id": code}).Decode(&invite)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && (invite.UsedBy != 0 || invite.Expired(time.Now()))) {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextInviteInvalid))
+	}
+	if err != nil {
+		return err
+	}
+
+	// mark as used first so that the same link can not be accepted twice concurrently
+	res, err := database.Collection("invites").UpdateOne(bgCtx,
+		bson.M{"_id": code, "used_by": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_by": chat.Id, "used_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount == 0 {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextInviteInvalid))
+	}
+
+	switch invite.Role {
+	case model.RolePharmacist:
+		if err := lp.bindPharmacist(bgCtx, chat.Id, invite.AptekaID); err != nil {
+			return err
+		}
+		apteka, err := lp.getApteka(bgCtx, invite.AptekaID)
+		if err != nil {
+			return err
+		}
+		log.Printf("[ChatId=%d] Joined apteka %s as pharmacist", chat.Id, apteka.ID.Hex())
+		lang := lp.lang(chat.Id)
+		return lp.sendText(chat.Id, i18n.T(lang, TextJoinedApteka)+"\n\n"+aptekaCard(lang, apteka)+"\n\n"+i18n.T(lang, TextAptekaUploadStock))
+	case model.RoleChainManager:
+		if err := lp.bindChainManager(bgCtx, chat.Id, invite.ChainID); err != nil {
+			return err
+		}
+		log.Printf("[ChatId=%d] Joined chain %s as manager", chat.Id, invite.ChainID.Hex())
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextJoinedChain))
+	}
+	return lp.sendText(chat.Id, lp.t(chat.Id, TextInviteInvalid))
+}
+
+// bindPharmacist moves the user to the apteka, chain managers and admins keep their role
+func (lp *LongPoll) bindPharmacist(ctx context.Context, chatId int64, aptekaID primitive.ObjectID) error {
+	database := lp.mongoClient.Database(db.Database)
+	var before model.User
+	err := database.Collection("users").FindOneAndUpdate(ctx,
+		bson.M{"user_id": chatId},
+		bson.M{"$set": bson.M{"apteka_id": aptekaID}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if before.AptekaId != aptekaID {
+		if err := lp.leaveApteka(ctx, chatId, before.AptekaId); err != nil {
+			return err
+		}
+	}
+	_, err = database.Collection("users").UpdateOne(ctx,
+		bson.M{"user_id": chatId, "role": bson.M{"$nin": bson.A{model.RoleChainManager, model.RoleAdmin}}},
+		bson.M{"$set": bson.M{"role": model.RolePharmacist}},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection("aptekas").UpdateOne(ctx,
+		bson.M{"_id": aptekaID},
+		bson.M{"$addToSet": bson.M{"owner_ids": chatId}},
+	)
+	return err
+}
+
+// bindChainManager makes the user a manager of the chain, admins keep their role
+func (lp *LongPoll) bindChainManager(ctx context.Context, chatId int64, chainID primitive.ObjectID) error {
+	database := lp.mongoClient.Database(db.Database)
+	_, err := database.Collection("users").UpdateOne(ctx,
+		bson.M{"user_id": chatId},
+		bson.M{"$set": bson.M{"chain_id": chainID}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+	var before model.User
+	err = database.Collection("users").FindOneAndUpdate(ctx,
+		bson.M{"user_id": chatId, "role": bson.M{"$ne": model.RoleAdmin}},
+		bson.M{
+			"$set":   bson.M{"role": model.RoleChainManager},
+			"$unset": bson.M{"apteka_id": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	// a manager works with the chain, not with the former apteka
+	if err := lp.leaveApteka(ctx, chatId, before.AptekaId); err != nil {
+		return err
+	}
+	_, err = database.Collection("chains").UpdateOne(ctx,
+		bson.M{"_id": chainID},
+		bson.M{"$addToSet": bson.M{"manager_ids": chatId}},
+	)
+	return err
+}
+
+// leaveApteka removes the user from the owners of the former apteka, so that
+// its reminders and stock uploads are no longer the user's
+func (lp *LongPoll) leaveApteka(ctx context.Context, chatId int64, aptekaID primitive.ObjectID) error {
+	if aptekaID.IsZero() {
+		return nil
+	}
+	_, err := lp.mongoClient.Database(db.Database).Collection("aptekas").UpdateOne(ctx,
+		bson.M{"_id": aptekaID},
+		bson.M{"$pull": bson.M{"owner_ids": chatId}},
+	)
+	if err == nil {
+		log.Printf("[ChatId=%d] Left apteka %s", chatId, aptekaID.Hex())
+	}
+	return err
+}
+
+// handleMyAptekas lists the branches of the pharmacist or the chain manager
+func (lp *LongPoll) handleMyAptekas(b *gotgbot.Bot, ctx *ext.Context) error {
+	chat := ctx.EffectiveMessage.Chat
+	bgCtx := context.Background()
+
+	user, err := lp.getUser(bgCtx, chat.Id)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.CanManageAptekas() {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextNotPharmacist))
+	}
+
+	aptekas, err := lp.managedAptekas(bgCtx, user)
+	if err != nil {
+		return err
+	}
+	if len(aptekas) == 0 {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextNoAptekas))
+	}
+
+	text, keyboard := aptekaCardsPage(lp.lang(chat.Id), aptekas, 1, time.Now())
+	opts := &gotgbot.SendMessageOpts{}
+	if len(keyboard) > 0 {
+		opts.ReplyMarkup = gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	}
+	_, err = b.SendMessage(chat.Id, text, opts)
+	return err
+}
+
+// handleMyAptekasCallback turns the page of /my_aptekas
+func (lp *LongPoll) handleMyAptekasCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	page, ok := pageOf(query.Data, myAptekasPrefix)
+	if !ok {
+		return nil
+	}
+	bgCtx := context.Background()
+	user, err := lp.getUser(bgCtx, chatId)
+	if err != nil || user == nil || !user.CanManageAptekas() {
+		return err
+	}
+	aptekas, err := lp.managedAptekas(bgCtx, user)
+	if err != nil || len(aptekas) == 0 {
+		return err
+	}
+
+	text, keyboard := aptekaCardsPage(lp.lang(chatId), aptekas, page, time.Now())
+	_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:      chatId,
+		MessageId:   query.Message.GetMessageId(),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	return err
+}
+
+// aptekaCardsPage shows a page of the branch cards with the stock age
+func aptekaCardsPage(lang i18n.Lang, aptekas []model.Apteka, page int, now time.Time) (string, [][]gotgbot.InlineKeyboardButton) {
+	page, pages, from, to := pageBounds(len(aptekas), aptekaCardsPageSize, page)
+	cards := make([]string, 0, to-from)
+	for _, apteka := range aptekas[from:to] {
+		cards = append(cards, aptekaCard(lang, &apteka)+"\n"+EmojiBox+" "+inventoryUpdated(lang, &apteka, now))
+	}
+	var keyboard [][]gotgbot.InlineKeyboardButton
+	if nav := pageNav(page, pages, myAptekasPrefix); len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+	return strings.Join(cards, "\n\n"), keyboard
+}
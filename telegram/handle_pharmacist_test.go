@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAptekasKeyboard(t *testing.T) {
+	aptekas := make([]model.Apteka, 23)
+	for i := range aptekas {
+		aptekas[i] = model.Apteka{ID: primitive.NewObjectID(), Name: "Аптека " + strconv.Itoa(i+1), Address: "Чуй 1"}
+	}
+	nav := func(keyboard [][]gotgbot.InlineKeyboardButton) []string {
+		var data []string
+		for _, button := range keyboard[len(keyboard)-1] {
+			data = append(data, button.CallbackData)
+		}
+		return data
+	}
+
+	tests := []struct {
+		page    int
+		first   int
+		buttons int
+		nav     []string
+	}{
+		{1, 0, 10, []string{"stock_page_2"}},
+		{2, 10, 10, []string{"stock_page_1", "stock_page_3"}},
+		{3, 20, 3, []string{"stock_page_2"}},
+		{7, 20, 3, []string{"stock_page_2"}},
+		{0, 0, 10, []string{"stock_page_2"}},
+	}
+	for _, tt := range tests {
+		keyboard := aptekasKeyboard(aptekas, tt.page, "stock_")
+		if len(keyboard) != tt.buttons+1 {
+			t.Errorf("page %d: %d rows, want %d and the arrows", tt.page, len(keyboard), tt.buttons)
+			continue
+		}
+		if got, want := keyboard[0][0].CallbackData, "stock_"+aptekas[tt.first].ID.Hex(); got != want {
+			t.Errorf("page %d starts with %s, want %s", tt.page, got, want)
+		}
+		if got := nav(keyboard); len(got) != len(tt.nav) || got[0] != tt.nav[0] || got[len(got)-1] != tt.nav[len(tt.nav)-1] {
+			t.Errorf("page %d arrows = %q, want %q", tt.page, got, tt.nav)
+		}
+	}
+
+	if keyboard := aptekasKeyboard(aptekas[:3], 1, "invite_"); len(keyboard) != 3 {
+		t.Errorf("a single page has %d rows, want no arrows", len(keyboard))
+	}
+	if keyboard := aptekasKeyboard(nil, 1, "invite_"); len(keyboard) != 0 {
+		t.Errorf("no aptekas gives %d rows", len(keyboard))
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	tests := []struct {
+		data string
+		page int
+		ok   bool
+	}{
+		{"invite_page_2", 2, true},
+		{"invite_page_0", 0, false},
+		{"invite_page_x", 0, false},
+		{"invite_manager", 0, false},
+		{"invite_" + primitive.NewObjectID().Hex(), 0, false},
+	}
+	for _, tt := range tests {
+		page, ok := pageOf(tt.data, "invite_")
+		if ok != tt.ok || (ok && page != tt.page) {
+			t.Errorf("pageOf(%q) = %d, %t, want %d, %t", tt.data, page, ok, tt.page, tt.ok)
+		}
+	}
+}
+
+func TestAptekaCardsPage(t *testing.T) {
+	aptekas := make([]model.Apteka, 12)
+	for i := range aptekas {
+		aptekas[i] = model.Apteka{
+			ID:      primitive.NewObjectID(),
+			Name:    strings.Repeat("Я", 100),
+			Address: strings.Repeat("Ж", 199) + strconv.Itoa(i%10),
+			Phone:   "+996555123456",
+		}
+	}
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		page  int
+		cards int
+		nav   []string
+	}{
+		{1, 5, []string{"myapt_page_2"}},
+		{2, 5, []string{"myapt_page_1", "myapt_page_3"}},
+		{3, 2, []string{"myapt_page_2"}},
+		{9, 2, []string{"myapt_page_2"}},
+	}
+	for _, tt := range tests {
+		text, keyboard := aptekaCardsPage(i18n.RU, aptekas, tt.page, now)
+		// the longest names and addresses still fit in a message
+		if n := utf8.RuneCountInString(text); n > 4096 {
+			t.Errorf("page %d has %d characters", tt.page, n)
+		}
+		if got := strings.Count(text, EmojiHospital); got != tt.cards {
+			t.Errorf("page %d has %d cards, want %d", tt.page, got, tt.cards)
+		}
+		var nav []string
+		for _, button := range keyboard[0] {
+			nav = append(nav, button.CallbackData)
+		}
+		if !slices.Equal(nav, tt.nav) {
+			t.Errorf("page %d arrows = %q, want %q", tt.page, nav, tt.nav)
+		}
+	}
+
+	if _, keyboard := aptekaCardsPage(i18n.RU, aptekas[:aptekaCardsPageSize], 1, now); len(keyboard) != 0 {
+		t.Errorf("a single page has arrows: %v", keyboard)
+	}
+}
@@ -1,66 +1,195 @@
 package telegram
 
 import (
-	"io"
+	"bytes"
+	"context"
+	"errors"
 	"log"
 	"net/http"
-	"os"
+	"slices"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
-	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/blob"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/imaging"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (lp *LongPoll) handlePhoto(b *gotgbot.Bot, ctx *ext.Context) error {
-	chat := ctx.EffectiveMessage.Chat
-	photos := ctx.EffectiveMessage.Photo
-
-	if len(photos) == 0 {
+	photo := largestPhoto(ctx.EffectiveMessage)
+	if photo == nil {
 		return nil
 	}
+	return lp.addPhoto(ctx.EffectiveMessage.Chat.Id, photo.FileId)
+}
+
+// addPhoto processes the photo and keeps it until the listing is submitted
+// or its photos are replaced
+func (lp *LongPoll) addPhoto(chatId int64, fileId string) error {
+	if len(lp.photos(chatId)) >= model.MaxHousePhotos {
+		return lp.sendText(chatId, lp.t(chatId, TextTooManyPhotos, model.MaxHousePhotos))
+	}
 
-	largestPhoto := photos[len(photos)-1]
-	photoId, err := lp.savePhoto(&largestPhoto)
+	photo, err := lp.savePhoto(fileId)
+	if errors.Is(err, imaging.ErrUnsupported) {
+		return lp.sendText(chatId, lp.t(chatId, TextPhotoUnsupported))
+	}
+	if errors.Is(err, blob.ErrTooBig) {
+		return lp.sendText(chatId, lp.t(chatId, TextPhotoTooBig, blob.MaxSize/(1024*1024)))
+	}
 	if err != nil {
 		return err
 	}
 
-	kv := lp.photoCache.Get(chat.Id)
-	if kv != nil {
-		lp.photoCache.Set(chat.Id, append(kv.Value(), photoId), ttlcache.DefaultTTL)
-	} else {
-		lp.photoCache.Set(chat.Id, []uuid.UUID{photoId}, ttlcache.DefaultTTL)
+	// the limit is checked again, the photos of an album are processed concurrently
+	if !lp.appendPhoto(chatId, *photo) {
+		return lp.sendText(chatId, lp.t(chatId, TextTooManyPhotos, model.MaxHousePhotos))
 	}
-	log.Printf("created: %#v", kv)
+	log.Printf("[ChatId=%d] Saved photo %s", chatId, photo.Original.Key)
 
 	return nil
 }
 
-func (lp *LongPoll) savePhoto(photo *gotgbot.PhotoSize) (photoId uuid.UUID, err error) {
-	file0, err := lp.bot.GetFile(photo.FileId, &gotgbot.GetFileOpts{})
+// photos returns the photos collected so far, the ones replacing the photos
+// of a listing if the user is replacing them
+func (lp *LongPoll) photos(chatId int64) []model.Photo {
+	if kv := lp.listingPhotos.Get(chatId); kv != nil {
+		return kv.Value().photos
+	}
+	if kv := lp.photoCache.Get(chatId); kv != nil {
+		return kv.Value()
+	}
+	return nil
+}
+
+// appendPhoto adds the photo unless the listing already has enough of them
+func (lp *LongPoll) appendPhoto(chatId int64, photo model.Photo) bool {
+	lp.photoMu.Lock()
+	defer lp.photoMu.Unlock()
+
+	photos := lp.photos(chatId)
+	if len(photos) >= model.MaxHousePhotos {
+		return false
+	}
+	// the cached slice may be held by a reader, it is never appended in place
+	photos = append(slices.Clip(photos), photo)
+	if kv := lp.listingPhotos.Get(chatId); kv != nil {
+		lp.listingPhotos.Set(chatId, listingPhotos{houseID: kv.Value().houseID, photos: photos}, ttlcache.DefaultTTL)
+	} else {
+		lp.photoCache.Set(chatId, photos, ttlcache.DefaultTTL)
+	}
+	return true
+}
+
+// preparingListing tells whether the user sends photos for a listing, edits
+// one or has a draft waiting to be published
+func (lp *LongPoll) preparingListing(ctx context.Context, chatId int64) (bool, error) {
+	if lp.photoCache.Has(chatId) || lp.listingPhotos.Has(chatId) || lp.houseEdits.Has(chatId) {
+		return true, nil
+	}
+	n, err := lp.mongoClient.Database(db.Database).Collection("houses").CountDocuments(ctx,
+		bson.M{"owner_id": chatId, "status": model.HouseDraft},
+		options.Count().SetLimit(1),
+	)
+	return n > 0, err
+}
+
+// savePhoto downloads the photo from Telegram, converts it to the gallery
+// sizes and puts them to the blob store
+func (lp *LongPoll) savePhoto(fileId string) (*model.Photo, error) {
+	file, err := lp.bot.GetFile(fileId, &gotgbot.GetFileOpts{})
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	resp, err := http.Get(file0.URL(lp.bot, &gotgbot.RequestOpts{}))
+	body, err := lp.download(file)
 	if err != nil {
-		return
+		return nil, err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
 
-	photoId = uuid.New()
-	file1, err := os.Create("photos/" + photoId.String() + ".jpg")
+	data, err := blob.ReadAll(body)
 	if err != nil {
-		return
+		return nil, err
+	}
+	variants, err := lp.imageProcessor.Process(data)
+	if err != nil {
+		return nil, err
 	}
-	//defer file1.Close()
 
-	// Write the reader's content to the file
-	_, err = io.Copy(file1, resp.Body)
+	photo := &model.Photo{}
+	for _, variant := range variants {
+		key, err := lp.blobStore.Put(context.Background(), bytes.NewReader(variant.Data))
+		if err != nil {
+			return nil, err
+		}
+		image := model.ImageVariant{Key: key, Width: variant.Width, Height: variant.Height}
+		switch variant.Size {
+		case imaging.Original:
+			photo.Original = image
+		case imaging.Medium:
+			photo.Medium = image
+		case imaging.Thumbnail:
+			photo.Thumbnail = image
+		}
+	}
+	return photo, nil
+}
+
+// GetPhoto serves GET /photos/{key}, keys are content hashes so the
+// response never changes and is cached forever, errors are not cached
+func (lp *LongPoll) GetPhoto(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	object, err := lp.blobStore.Get(r.Context(), key)
+	if errors.Is(err, blob.ErrNotFound) {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Error(w, "photo not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
+		log.Printf("[GetPhoto] Could not load photo %s: %s", key, err.Error())
+		w.Header().Set("Cache-Control", "no-store")
+		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
+	defer object.Close()
 
-	return
+	info := object.Info()
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("ETag", `"`+info.Key+`"`)
+	http.ServeContent(&cacheOnSuccess{ResponseWriter: w}, r, info.Key, info.ModTime, object)
+}
+
+// cacheOnSuccess adds the immutable cache headers only to successful
+// responses, a failed read of the blob must not be cached for a year
+type cacheOnSuccess struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *cacheOnSuccess) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code < http.StatusBadRequest {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Del("ETag")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheOnSuccess) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
 }
@@ -0,0 +1,154 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/blob"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testPhotoKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef.jpg"
+
+// photoStore serves one photo, other keys are missing
+type photoStore struct {
+	blob.Store
+	err     error
+	seekErr error
+}
+
+func (s photoStore) Get(ctx context.Context, key string) (blob.Object, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if key != testPhotoKey {
+		return nil, blob.ErrNotFound
+	}
+	return &photoObject{Reader: bytes.NewReader([]byte("jpeg")), seekErr: s.seekErr}, nil
+}
+
+type photoObject struct {
+	*bytes.Reader
+	seekErr error
+}
+
+func (o *photoObject) Seek(offset int64, whence int) (int64, error) {
+	if o.seekErr != nil {
+		return 0, o.seekErr
+	}
+	return o.Reader.Seek(offset, whence)
+}
+
+func (o *photoObject) Close() error { return nil }
+
+func (o *photoObject) Info() blob.Info {
+	return blob.Info{Key: testPhotoKey, ContentType: "image/jpeg", Size: 4, ModTime: time.Unix(1700000000, 0)}
+}
+
+func TestGetPhoto(t *testing.T) {
+	immutable := "public, max-age=31536000, immutable"
+	tests := []struct {
+		name        string
+		store       photoStore
+		key         string
+		ifNoneMatch string
+		status      int
+		cache       string
+		body        string
+	}{
+		{"found", photoStore{}, testPhotoKey, "", http.StatusOK, immutable, "jpeg"},
+		{"not modified", photoStore{}, testPhotoKey, `"` + testPhotoKey + `"`, http.StatusNotModified, immutable, ""},
+		{"missing", photoStore{}, "ff" + testPhotoKey[2:], "", http.StatusNotFound, "no-store", ""},
+		{"storage down", photoStore{err: errors.New("connection refused")}, testPhotoKey, "", http.StatusInternalServerError, "no-store", ""},
+		{"broken read", photoStore{seekErr: errors.New("connection reset")}, testPhotoKey, "", http.StatusInternalServerError, "no-store", ""},
+	}
+	for _, tt := range tests {
+		lp := &LongPoll{blobStore: tt.store}
+		r := httptest.NewRequest(http.MethodGet, "/photos/"+tt.key, nil)
+		r = mux.SetURLVars(r, map[string]string{"key": tt.key})
+		if tt.ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		lp.GetPhoto(w, r)
+
+		resp := w.Result()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != tt.cache {
+			t.Errorf("%s: Cache-Control %q, want %q", tt.name, got, tt.cache)
+		}
+		if tt.status >= http.StatusBadRequest && resp.Header.Get("ETag") != "" {
+			t.Errorf("%s: ETag is sent with an error", tt.name)
+		}
+		if tt.body != "" && string(body) != tt.body {
+			t.Errorf("%s: body %q, want %q", tt.name, body, tt.body)
+		}
+	}
+}
+
+func TestAppendPhotoConcurrent(t *testing.T) {
+	lp := &LongPoll{photoCache: ttlcache.New[int64, []model.Photo](), listingPhotos: ttlcache.New[int64, listingPhotos]()}
+	const chatId, album = 1, 25
+
+	var wg sync.WaitGroup
+	var added atomic.Int32
+	for i := range album {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			photo := model.Photo{Original: model.ImageVariant{Key: strconv.Itoa(i)}}
+			if lp.appendPhoto(chatId, photo) {
+				added.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	photos := lp.photoCache.Get(chatId).Value()
+	if len(photos) != model.MaxHousePhotos || int(added.Load()) != model.MaxHousePhotos {
+		t.Errorf("%d photos kept, %d reported added, want %d", len(photos), added.Load(), model.MaxHousePhotos)
+	}
+	keys := map[string]bool{}
+	for _, photo := range photos {
+		keys[photo.Original.Key] = true
+	}
+	if len(keys) != len(photos) {
+		t.Errorf("photos are duplicated: %v", keys)
+	}
+}
+
+func TestAppendPhotoReplacing(t *testing.T) {
+	lp := &LongPoll{photoCache: ttlcache.New[int64, []model.Photo](), listingPhotos: ttlcache.New[int64, listingPhotos]()}
+	draft := model.Photo{Original: model.ImageVariant{Key: "draft"}}
+	replacing := model.Photo{Original: model.ImageVariant{Key: "replacing"}}
+	houseID := primitive.NewObjectID()
+
+	lp.appendPhoto(1, draft)
+	lp.listingPhotos.Set(1, listingPhotos{houseID: houseID}, ttlcache.DefaultTTL)
+	lp.appendPhoto(1, replacing)
+
+	// the photos of the draft are kept while the ones of a listing are replaced
+	if got := lp.photoCache.Get(1).Value(); !slices.Equal(got, []model.Photo{draft}) {
+		t.Errorf("draft photos = %v, want %v", got, []model.Photo{draft})
+	}
+	got := lp.listingPhotos.Get(1).Value()
+	if got.houseID != houseID || !slices.Equal(got.photos, []model.Photo{replacing}) {
+		t.Errorf("listing photos = %+v, want %v of %s", got, []model.Photo{replacing}, houseID.Hex())
+	}
+}
@@ -1,15 +1,167 @@
 package telegram
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/search"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AptekaPayload struct {
-	Name      string   `json:"name"`
-	Phone     string   `json:"phone"`
-	Address   string   `json:"address"`
+	Name      string `json:"name"`
+	Phone     string `json:"phone"`
+	Address   string `json:"address"`
+	WorkHours string `json:"work_hours"`
+	Open      bool   `json:"open"`
+	Status    string `json:"status"`
+	// Stale is set when the stock was not updated for a long time
+	Stale    bool         `json:"stale"`
+	Location *model.Point `json:"location,omitempty"`
+	// Distance to the user in meters, omitted if the location is unknown
+	Distance  int      `json:"distance,omitempty"`
+	MapURL    string   `json:"map_url"`
 	Medicines []string `json:"medicines"`
 }
 
+// GetRequest serves GET /apteka/search/{id} for the search results mini-app
 func (lp *LongPoll) GetRequest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "search request not found", http.StatusNotFound)
+		return
+	}
+
+	request, err := lp.getSearchRequest(r.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && request.Expired(time.Now())) {
+		http.Error(w, "search request not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[GetRequest] Could not load search request %s: %s", id, err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// the mini-app may send a fresher location of the user
+	location := request.Location
+	if point, ok := locationQuery(r); ok {
+		location = point
+	}
+
+	payload := lp.searchAptekas(lp.lang(request.ChatID), request.Medicines, location, searchResultsLimit)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payload)
+}
+
+// locationQuery reads optional ?lat=&lon= parameters
+func locationQuery(r *http.Request) (*model.Point, bool) {
+	lat, err1 := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lon, err2 := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+	if err1 != nil || err2 != nil {
+		return nil, false
+	}
+	point := model.NewPoint(lat, lon)
+	return point, point.IsValid()
+}
+
+func (lp *LongPoll) createSearchRequest(ctx context.Context, chatID int64, text string, medicines []model.RequestedMedicine) (*model.SearchRequest, error) {
+	request := &model.SearchRequest{
+		ID:        uuid.New().String(),
+		ChatID:    chatID,
+		Text:      text,
+		Medicines: medicines,
+		CreatedAt: time.Now(),
+	}
+	if user, err := lp.getUser(ctx, chatID); err == nil && user != nil {
+		request.Location = user.Location
+	}
+
+	coll := lp.mongoClient.Database(db.Database).Collection("search_requests")
+	if _, err := coll.InsertOne(ctx, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (lp *LongPoll) getSearchRequest(ctx context.Context, id string) (*model.SearchRequest, error) {
+	coll := lp.mongoClient.Database(db.Database).Collection("search_requests")
+
+	var request model.SearchRequest
+	if err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+const searchResultsLimit = 100
+
+// searchAptekas ranks aptekas by the requested medicines resolved in the catalog
+// and by the distance to the location if it is known, the status is in the language
+func (lp *LongPoll) searchAptekas(lang i18n.Lang, medicines []model.RequestedMedicine, location *model.Point, limit int) []AptekaPayload {
+	medicineIDs := make([]primitive.ObjectID, 0, len(medicines))
+	for _, requested := range medicines {
+		if requested.Resolved() {
+			medicineIDs = append(medicineIDs, requested.MedicineID)
+		}
+	}
+
+	catalog := lp.catalog.Load()
+	// brands of the same substance are interchangeable
+	generics := map[primitive.ObjectID][]primitive.ObjectID{}
+	for _, id := range medicineIDs {
+		for _, generic := range catalog.Generics(id) {
+			generics[id] = append(generics[id], generic.ID)
+		}
+	}
+
+	index := lp.engine.Index()
+	now := time.Now()
+	results := index.Search(search.Query{
+		MedicineIDs: medicineIDs,
+		Generics:    generics,
+		Location:    location,
+		Now:         now,
+		Freshness:   lp.inventoryFreshness,
+		Limit:       limit,
+	})
+
+	payload := make([]AptekaPayload, 0, len(results))
+	for _, result := range results {
+		names := make([]string, 0, len(result.Medicines))
+		for _, id := range result.Medicines {
+			if m := catalog.Get(id); m != nil {
+				names = append(names, m.Name)
+			} else {
+				names = append(names, index.Name(id))
+			}
+		}
+		payload = append(payload, AptekaPayload{
+			Name:      result.Apteka.Name,
+			Phone:     result.Apteka.Phone,
+			Address:   result.Apteka.Address,
+			WorkHours: result.Apteka.WorkHours.String(),
+			Open:      result.Open,
+			Status:    workStatus(lang, result.Apteka.WorkHours, now),
+			Stale:     result.Stale,
+			Location:  result.Apteka.Location,
+			Distance:  int(result.Distance),
+			MapURL:    mapURL(result.Apteka),
+			Medicines: names,
+		})
+	}
+	return payload
 }
@@ -49,7 +49,7 @@ func (lp *LongPoll) sendToChatGPT(userID int64, messages []openai.ChatCompletion
 		log.Printf("Ошибка при получении истории сообщений: %v", err)
 		return "", err
 	}
-	log.Printf("История сообщений для sessionID=%s: %+v", userID, historyMessages)
+	log.Printf("История сообщений для userID=%d: %+v", userID, historyMessages)
 
 	// Добавляем историю сообщений к текущим сообщениям
 	messages = append(historyMessages, messages...)
@@ -71,6 +71,8 @@ func (lp *LongPoll) sendToChatGPT(userID int64, messages []openai.ChatCompletion
 		}}
 	}
 
+	messages = lp.resolveImages(messages)
+
 	// Отправляем запрос в OpenAI API
 	resp, err := client.CreateChatCompletion(context.TODO(), openai.ChatCompletionRequest{
 		Model:     openai.GPT4o,
@@ -147,7 +149,7 @@ func (lp *LongPoll) findLastClosedSession(userID int64) (string, bson.M, error)
 			"user_id":   userID,
 			"is_closed": true,
 		},
-		options.FindOne().SetSort(bson.D{{"created_at", -1}}),
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
 	).Decode(&closedSession)
 
 	if err != nil {
@@ -201,7 +203,7 @@ func (lp *LongPoll) incrementUserMessageCount(sessionID string) error {
 	return err
 }
 
-func (lp *LongPoll) saveUserMessage(userID int64, sessionID string, message string) error {
+func (lp *LongPoll) saveUserMessage(userID int64, sessionID string, message string, attachments ...dialogAttachment) error {
 	collection := lp.mongoClient.Database(db.Database).Collection("dialogues")
 
 	// Создаем сообщение от пользователя
@@ -212,6 +214,9 @@ func (lp *LongPoll) saveUserMessage(userID int64, sessionID string, message stri
 		"text":       message,
 		"timestamp":  time.Now().Format(time.RFC3339), // Время сообщения
 	}
+	if len(attachments) > 0 {
+		userMessage["attachments"] = attachments
+	}
 
 	_, err := collection.InsertOne(context.TODO(), userMessage)
 	if err != nil {
@@ -272,9 +277,32 @@ func (lp *LongPoll) getAllMessages(userID int64) ([]openai.ChatCompletionMessage
 			continue
 		}
 
+		attachments := decodeAttachments(msg["attachments"])
+		if len(attachments) == 0 {
+			conversation = append(conversation, openai.ChatCompletionMessage{
+				Role:    role,
+				Content: text,
+			})
+			continue
+		}
+
+		// Картинки передаем ссылками на файлы телеграма, они скачиваются перед запросом к GPT
+		var parts []openai.ChatMessagePart
+		if text != "" {
+			parts = append(parts, openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: text})
+		}
+		for _, attachment := range attachments {
+			parts = append(parts, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{
+					URL:    telegramFileScheme + attachment.FileId,
+					Detail: openai.ImageURLDetailAuto,
+				},
+			})
+		}
 		conversation = append(conversation, openai.ChatCompletionMessage{
-			Role:    role,
-			Content: text,
+			Role:         role,
+			MultiContent: parts,
 		})
 	}
 
@@ -321,28 +349,28 @@ func (lp *LongPoll) canStartSession(userID int64) (bool, string, error) {
 		if err != nil {
 			return false, "", err
 		}
-		return true, "Trial activated! You have 2 sessions.", nil
+		return true, lp.t(userID, TextSessionTrial), nil
 	}
 
 	// Проверяем, не истекла ли подписка
 	endDate, ok := user["subscription_end"].(time.Time)
 
 	if ok && time.Now().After(endDate) {
-		return false, "Ваша подписка истекла. Приобретите новую", nil
+		return false, lp.t(userID, TextSessionExpired), nil
 	}
 
 	unlimited, ok := user["unlimited_sessions"].(bool)
 	if ok && unlimited {
-		return true, "У вас неограниченные сессии.", nil
+		return true, lp.t(userID, TextSessionUnlimited), nil
 	}
 
 	// Проверяем, остались ли сессии
 	sessionsLeft, ok := user["sessions_left"].(int32)
 	if ok && sessionsLeft <= 0 {
-		return false, "У вас не осталось сессий. Купите новые", nil
+		return false, lp.t(userID, TextSessionNoLeft), nil
 	}
 
-	return true, "Вы можете начать сессиюю.", nil
+	return true, lp.t(userID, TextSessionAllowed), nil
 }
 
 func (lp *LongPoll) buySubscription(userID int64, plan string) error {
@@ -0,0 +1,179 @@
+package telegram
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/google/uuid"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/medicine"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// callback data is dym:<request id>:<medicine index>:<catalog id>
+const suggestionPrefix = "dym:"
+
+const suggestionLimit = 3
+
+// not more names than this are asked about in one search
+const suggestionMaxMedicines = 3
+
+type medicineSuggestions struct {
+	index   int
+	matches []medicine.Match
+}
+
+// suggestMedicines finds catalog entries for the names that were not matched confidently
+func (lp *LongPoll) suggestMedicines(request *model.SearchRequest) []medicineSuggestions {
+	catalog := lp.catalog.Load()
+
+	var result []medicineSuggestions
+	for i, requested := range request.Medicines {
+		if requested.Score >= medicine.ConfidentScore || len(result) == suggestionMaxMedicines {
+			continue
+		}
+		matches := catalog.Suggest(requested.Name, suggestionLimit)
+		if len(matches) > 0 {
+			result = append(result, medicineSuggestions{index: i, matches: matches})
+		}
+	}
+	return result
+}
+
+func (lp *LongPoll) sendSuggestions(request *model.SearchRequest, suggestions []medicineSuggestions) error {
+	for _, s := range suggestions {
+		var keyboard [][]gotgbot.InlineKeyboardButton
+		for _, match := range s.matches {
+			keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+				Text:         match.Medicine.Name,
+				CallbackData: suggestionData(request.ID, s.index, match.Medicine.ID),
+			}})
+		}
+
+		name := request.Medicines[s.index].Name
+		_, err := lp.bot.SendMessage(request.ChatID, lp.t(request.ChatID, TextDidYouMean, name), &gotgbot.SendMessageOpts{
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// suggestionData packs the request id as base64 so that the data fits 64 bytes
+func suggestionData(requestID string, index int, medicineID primitive.ObjectID) string {
+	id := uuid.MustParse(requestID)
+	return suggestionPrefix + base64.RawURLEncoding.EncodeToString(id[:]) + ":" +
+		strconv.Itoa(index) + ":" + medicineID.Hex()
+}
+
+func parseSuggestionData(data string) (requestID string, index int, medicineID primitive.ObjectID, err error) {
+	parts := strings.Split(strings.TrimPrefix(data, suggestionPrefix), ":")
+	if len(parts) != 3 {
+		return "", 0, primitive.NilObjectID, errors.New("malformed suggestion " + data)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", 0, primitive.NilObjectID, err
+	}
+	id, err := uuid.FromBytes(raw)
+	if err != nil {
+		return "", 0, primitive.NilObjectID, err
+	}
+	if index, err = strconv.Atoi(parts[1]); err != nil {
+		return "", 0, primitive.NilObjectID, err
+	}
+	if medicineID, err = primitive.ObjectIDFromHex(parts[2]); err != nil {
+		return "", 0, primitive.NilObjectID, err
+	}
+	return id.String(), index, medicineID, nil
+}
+
+// handleSuggestionCallback replaces the uncertain name with the chosen
+// catalog medicine and repeats the search
+func (lp *LongPoll) handleSuggestionCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	chatId := query.From.Id
+	_, _ = query.Answer(b, nil)
+
+	requestID, index, medicineID, err := parseSuggestionData(query.Data)
+	if err != nil {
+		log.Printf("[ChatId=%d] %s", chatId, err.Error())
+		return nil
+	}
+
+	bgCtx := context.Background()
+	request, err := lp.getSearchRequest(bgCtx, requestID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && request.Expired(time.Now())) {
+		return lp.sendText(chatId, lp.t(chatId, TextSuggestionExpired))
+	}
+	if err != nil {
+		return err
+	}
+	if request.ChatID != chatId || index < 0 || index >= len(request.Medicines) {
+		return nil
+	}
+	chosen := lp.catalog.Load().Get(medicineID)
+	if chosen == nil {
+		return lp.sendText(chatId, lp.t(chatId, TextSuggestionExpired))
+	}
+
+	field := "medicines." + strconv.Itoa(index)
+	_, err = lp.mongoClient.Database(db.Database).Collection("search_requests").UpdateOne(bgCtx,
+		bson.M{"_id": request.ID},
+		bson.M{"$set": bson.M{
+			field + ".name":        chosen.Name,
+			field + ".medicine_id": chosen.ID,
+			field + ".score":       1.0,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	request.Medicines[index].Name = chosen.Name
+	request.Medicines[index].MedicineID = chosen.ID
+	request.Medicines[index].Score = 1
+
+	_, _, _ = b.EditMessageText("✅ "+chosen.Name, &gotgbot.EditMessageTextOpts{
+		ChatId:    chatId,
+		MessageId: query.Message.GetMessageId(),
+	})
+	return lp.sendSearchResults(request)
+}
+
+// unresolvedNames are the requested names that are neither matched to the
+// catalog nor offered as suggestions
+func unresolvedNames(medicines []model.RequestedMedicine, suggestions []medicineSuggestions) []string {
+	suggested := map[int]bool{}
+	for _, s := range suggestions {
+		suggested[s.index] = true
+	}
+	var names []string
+	for i, m := range medicines {
+		if !m.Resolved() && !suggested[i] {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+func anyResolved(medicines []model.RequestedMedicine) bool {
+	for _, m := range medicines {
+		if m.Resolved() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package telegram
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnresolvedNames(t *testing.T) {
+	medicines := []model.RequestedMedicine{
+		{Name: "Нурофен", MedicineID: primitive.NewObjectID(), Score: 1},
+		{Name: "Нурафен"},
+		{Name: "Абракадабра"},
+		{Name: "Панадол", MedicineID: primitive.NewObjectID(), Score: 0.8},
+		{Name: "Ксюша"},
+	}
+	suggestions := []medicineSuggestions{{index: 1}, {index: 3}}
+
+	got := unresolvedNames(medicines, suggestions)
+	want := []string{"Абракадабра", "Ксюша"}
+	if !slices.Equal(got, want) {
+		t.Errorf("unresolvedNames() = %q, want %q", got, want)
+	}
+	if got := unresolvedNames(medicines[:1], nil); got != nil {
+		t.Errorf("unresolvedNames(resolved) = %q, want none", got)
+	}
+}
+
+func TestSuggestionData(t *testing.T) {
+	requestID := uuid.New().String()
+	medicineID := primitive.NewObjectID()
+
+	data := suggestionData(requestID, 2, medicineID)
+	if len(data) > 64 {
+		t.Errorf("callback data is %d bytes, Telegram allows 64", len(data))
+	}
+	gotRequest, gotIndex, gotMedicine, err := parseSuggestionData(data)
+	if err != nil || gotRequest != requestID || gotIndex != 2 || gotMedicine != medicineID {
+		t.Errorf("parseSuggestionData(%q) = %s, %d, %s, %v", data, gotRequest, gotIndex, gotMedicine, err)
+	}
+
+	for _, data := range []string{"dym:", "dym:a:b:c", suggestionPrefix + "AAAA:x:" + medicineID.Hex()} {
+		if _, _, _, err := parseSuggestionData(data); err == nil {
+			t.Errorf("parseSuggestionData(%q) succeeded", data)
+		}
+	}
+}
@@ -1,59 +1,207 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
-	"fmt"
-	"net/http"
+	"log"
+	"strings"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
-	"github.com/sashabaranov/go-openai"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/voice"
 )
 
+const searchResultsWebAppUrl = "https://wolfrepos.github.io/apteka/search/index.html"
+
+// longer recordings are not transcribed
+const maxVoiceDuration = 10 * 60
+
 func (lp *LongPoll) handleVoice(b *gotgbot.Bot, ctx *ext.Context) error {
 	chat := ctx.EffectiveMessage.Chat
-	voice := ctx.EffectiveMessage.Voice
+	sp := messageSpeech(ctx.EffectiveMessage)
 
-	if voice.Duration > 20 {
-		return lp.sendText(chat.Id, TextTooLongVoice)
+	if sp.Duration > maxVoiceDuration || sp.FileSize > maxBotFileSize {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextTooLongVoice))
 	}
 
-	lp.sendText(chat.Id, "Ищу подходящие аптеки")
+	lp.sendText(chat.Id, lp.t(chat.Id, TextSearching))
 
-	_, err := lp.transcribeVoice(voice)
+	result, err := lp.transcribe(sp, "")
 	if err != nil {
 		return err
 	}
+	log.Printf("[ChatId=%d] Transcribed voice (%s): %s", chat.Id, result.Language, result.Text)
+
+	return lp.searchByText(chat.Id, result.Text)
+}
+
+// searchByText extracts medicines from the text, stores the search request
+// and replies with the number of found aptekas and a button to the results
+func (lp *LongPoll) searchByText(chatId int64, text string) error {
+	ctx := context.Background()
+
+	medicines := lp.extractor.Extract(ctx, text, lp.catalog.Load())
+	log.Printf("[ChatId=%d] Extracted medicines: %+v", chatId, medicines)
+	if len(medicines) == 0 {
+		return lp.sendText(chatId, lp.t(chatId, TextNoMedicines))
+	}
+
+	request, err := lp.createSearchRequest(ctx, chatId, text, medicines)
+	if err != nil {
+		return err
+	}
+
+	suggestions := lp.suggestMedicines(request)
+	// these names are not searched at all, the user should know why
+	if names := unresolvedNames(request.Medicines, suggestions); len(names) > 0 {
+		if err := lp.sendText(chatId, lp.t(chatId, TextNotInCatalog, strings.Join(names, ", "))); err != nil {
+			return err
+		}
+	}
+	// nothing to search yet, the user chooses among suggestions first
+	if !anyResolved(request.Medicines) {
+		return lp.sendSuggestions(request, suggestions)
+	}
+	if err := lp.sendSearchResults(request); err != nil {
+		return err
+	}
+	return lp.sendSuggestions(request, suggestions)
+}
+
+// sendSearchResults sends the number of found aptekas with the button opening
+// the mini-app and the nearest apteka on the map
+func (lp *LongPoll) sendSearchResults(request *model.SearchRequest) error {
+	chatId := request.ChatID
+	lang := lp.lang(chatId)
+	// the mini-app shows at most searchResultsLimit aptekas, so the count does too
+	aptekas := lp.searchAptekas(lang, request.Medicines, request.Location, searchResultsLimit)
+	if len(aptekas) == 0 {
+		return lp.sendText(chatId, i18n.T(lang, TextNothingFound))
+	}
+
+	keyboard := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+			{Text: i18n.T(lang, ButtonShow), WebApp: &gotgbot.WebAppInfo{Url: searchResultsWebAppUrl + "?id=" + request.ID}},
+		}},
+	}
+	_, err := lp.bot.SendMessage(chatId, i18n.N(lang, TextFoundAptekas, len(aptekas), len(aptekas)), &gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+	if err != nil {
+		return err
+	}
+
+	if top := aptekas[0]; top.Location != nil {
+		address := top.Address
+		if top.Distance > 0 {
+			address += " · " + formatDistance(lang, top.Distance)
+		}
+		_, err = lp.bot.SendVenue(chatId, top.Location.Lat(), top.Location.Lon(), top.Name, address, &gotgbot.SendVenueOpts{
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+				InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{Text: i18n.T(lang, ButtonOpen2GIS), Url: top.MapURL}}},
+			},
+		})
+		if err != nil {
+			log.Printf("[ChatId=%d] Could not send venue: %s", chatId, err.Error())
+		}
+	}
+
+	if request.Location == nil {
+		_, err = lp.bot.SendMessage(chatId, i18n.T(lang, TextAskLocation), &gotgbot.SendMessageOpts{ReplyMarkup: locationKeyboard(lang)})
+	}
+	return err
+}
 
+// speech is a voice, audio or video note message
+type speech struct {
+	FileId   string
+	FileName string
+	Duration int64
+	FileSize int64
+}
+
+func messageSpeech(msg *gotgbot.Message) *speech {
+	switch {
+	case msg.Voice != nil:
+		return &speech{FileId: msg.Voice.FileId, Duration: msg.Voice.Duration, FileSize: msg.Voice.FileSize}
+	case msg.Audio != nil:
+		return &speech{FileId: msg.Audio.FileId, FileName: msg.Audio.FileName, Duration: msg.Audio.Duration, FileSize: msg.Audio.FileSize}
+	case msg.VideoNote != nil:
+		return &speech{FileId: msg.VideoNote.FileId, Duration: msg.VideoNote.Duration, FileSize: msg.VideoNote.FileSize}
+	}
 	return nil
 }
 
-func (lp *LongPoll) transcribeVoice(voice *gotgbot.Voice) (string, error) {
-	file, err := lp.bot.GetFile(voice.FileId, &gotgbot.GetFileOpts{})
+// transcribe downloads the recording and recognizes it, the language is
+// detected if empty
+func (lp *LongPoll) transcribe(sp *speech, language string) (*voice.Result, error) {
+	file, err := lp.bot.GetFile(sp.FileId, &gotgbot.GetFileOpts{})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	resp, err := http.Get(file.URL(lp.bot, &gotgbot.RequestOpts{}))
+	body, err := lp.download(file)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
 
-	req := openai.AudioRequest{
-		Model:    openai.Whisper1,
-		Reader:   resp.Body,
-		FilePath: file.FilePath,
-		Prompt:   "Парацетамол, ТайлолХот, Тримол",
-		Language: "ru",
+	fileName := file.FilePath
+	if sp.FileName != "" {
+		fileName = sp.FileName
 	}
+	return lp.transcriber.Transcribe(context.Background(), fileName, body, lp.voiceVocabulary(), language)
+}
 
-	context := context.Background()
-	openaiResp, err := lp.openaiClient.CreateTranscription(context, req)
+// voiceVocabulary hints Whisper with the medicines stocked in most aptekas
+func (lp *LongPoll) voiceVocabulary() []string {
+	catalog := lp.catalog.Load()
+
+	var names []string
+	for _, id := range lp.engine.Index().Popular() {
+		if m := catalog.Get(id); m != nil {
+			names = append(names, m.Name)
+		}
+	}
+	if len(names) == 0 {
+		names = catalog.Names()
+	}
+	return names
+}
+
+// transcribeDialogSpeech recognizes a voice message sent in a dialog and shows
+// the recognized text to the user, empty text means the user was already answered
+func (lp *LongPoll) transcribeDialogSpeech(chatId int64, sp *speech) (string, error) {
+	if sp.Duration > maxVoiceDuration || sp.FileSize > maxBotFileSize {
+		return "", lp.sendText(chatId, lp.t(chatId, TextTooLongVoice))
+	}
+
+	result, err := lp.transcribe(sp, "")
 	if err != nil {
-		fmt.Printf("Transcription error: %v\n", err)
+		log.Printf("[ChatId=%d] Could not transcribe voice: %s", chatId, err.Error())
+		return "", lp.sendText(chatId, lp.t(chatId, TextVoiceNotRecognized))
+	}
+	text := strings.TrimSpace(result.Text)
+	if text == "" {
+		return "", lp.sendText(chatId, lp.t(chatId, TextVoiceNotRecognized))
+	}
+
+	if err := lp.sendText(chatId, lp.t(chatId, TextYouSaid, text)); err != nil {
 		return "", err
 	}
+	return text, nil
+}
 
-	return openaiResp.Text, nil
+// sendSpeech sends the text as a voice message, failures are only logged
+// because the text is already delivered
+func (lp *LongPoll) sendSpeech(chatId int64, text string) {
+	audio, err := lp.speaker.Speak(context.Background(), text)
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not synthesize speech: %s", chatId, err.Error())
+		return
+	}
+	_, err = lp.bot.SendVoice(chatId, gotgbot.InputFileByReader("reply.ogg", bytes.NewReader(audio)), nil)
+	if err != nil {
+		log.Printf("[ChatId=%d] Could not send voice: %s", chatId, err.Error())
+	}
 }
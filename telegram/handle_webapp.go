@@ -2,13 +2,21 @@ package telegram
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
 	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/phone"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func (lp *LongPoll) handleWebAppData(b *gotgbot.Bot, ctx *ext.Context) error {
@@ -30,27 +38,163 @@ func (lp *LongPoll) handleWebAppData(b *gotgbot.Bot, ctx *ext.Context) error {
 	json := webAppData.Data
 	log.Printf("[ChatId=%d] Got json from WebApp: %s", chat.Id, json)
 
-	if house, err := model.ParseAndValidate[model.House](json); err == nil {
-		//house.OwnerID = chat.Id
-		//house.Active = true
-		//kv, _ := lp.photoCache.GetAndDelete(chat.Id)
-		//if kv != nil {
-		//	house.PhotoIDs = kv.Value()
-		//}
+	webAppType, err := model.WebAppType(json)
+	if err != nil {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextWebAppError))
+	}
+
+	switch webAppType {
+	case "apteka":
+		apteka, err := model.ParseAndValidate[model.Apteka](json)
+		if err != nil {
+			return lp.sendValidationError(chat.Id, err)
+		}
+		return lp.handleWebAppApteka(chat, apteka)
+	case "house", "":
+		house, err := model.ParseAndValidate[model.House](json)
+		if err != nil {
+			return lp.sendValidationError(chat.Id, err)
+		}
 		return lp.handleWebAppHouse(chat, house)
 	}
 
-	return lp.sendText(chat.Id, "Что-то пошло не так - попробуйте еще раз")
+	return lp.sendText(chat.Id, lp.t(chat.Id, TextWebAppError))
 }
 
-func (lp *LongPoll) handleWebAppHouse(chat *gotgbot.Chat, house *model.House) error {
-	coll := lp.mongoClient.Database(db.Database).Collection("houses")
-	_, err := coll.InsertOne(context.Background(), house)
+// sendValidationError lists the wrong fields so that the user can fix the form
+func (lp *LongPoll) sendValidationError(chatId int64, err error) error {
+	var validationErr *model.ValidationError
+	if !errors.As(err, &validationErr) {
+		log.Printf("[ChatId=%d] Malformed web app data: %s", chatId, err.Error())
+		return lp.sendText(chatId, lp.t(chatId, TextWebAppError))
+	}
+	for _, f := range validationErr.Fields {
+		if f.Err != nil {
+			log.Printf("[ChatId=%d] Wrong %s: %s", chatId, f.Field, f.Err.Error())
+		}
+	}
+	return lp.sendText(chatId, TextValidationErrors(lp.lang(chatId), validationErr.Fields))
+}
+
+// ValidateWebApp serves POST /webapp/validate, the web app checks the form
+// before sending it to the bot and shows the errors next to the fields
+func (lp *LongPoll) ValidateWebApp(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64*1024))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+	raw := string(body)
+
+	webAppType, err := model.WebAppType(raw)
+	if err != nil {
+		http.Error(w, "malformed json", http.StatusBadRequest)
+		return
+	}
+	switch webAppType {
+	case "apteka":
+		_, err = model.ParseAndValidate[model.Apteka](raw)
+	case "house", "":
+		_, err = model.ParseAndValidate[model.House](raw)
+	default:
+		http.Error(w, "unknown type "+webAppType, http.StatusBadRequest)
+		return
+	}
+
+	var validationErr *model.ValidationError
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, validationPayload(requestLang(r), nil))
+	case errors.As(err, &validationErr):
+		writeJSON(w, http.StatusUnprocessableEntity, validationPayload(requestLang(r), validationErr.Fields))
+	default:
+		http.Error(w, "malformed json", http.StatusBadRequest)
+	}
+}
+
+// FieldErrorPayload is a wrong field with the text for the user
+type FieldErrorPayload struct {
+	model.FieldError
+	Message string `json:"message"`
+}
+
+type ValidationPayload struct {
+	Errors []FieldErrorPayload `json:"errors"`
+}
+
+func validationPayload(lang i18n.Lang, fields []model.FieldError) ValidationPayload {
+	payload := ValidationPayload{Errors: make([]FieldErrorPayload, 0, len(fields))}
+	for _, f := range fields {
+		payload.Errors = append(payload.Errors, FieldErrorPayload{FieldError: f, Message: TextFieldError(lang, f)})
+	}
+	return payload
+}
+
+// requestLang is the lang parameter the web app takes from Telegram, or
+// the first supported language of the Accept-Language header
+func requestLang(r *http.Request) i18n.Lang {
+	if lang, ok := i18n.Parse(r.URL.Query().Get("lang")); ok {
+		return lang
+	}
+	for _, tag := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ = strings.Cut(tag, ";")
+		if lang, ok := i18n.Parse(tag); ok {
+			return lang
+		}
+	}
+	return i18n.Default
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (lp *LongPoll) handleWebAppApteka(chat *gotgbot.Chat, apteka *model.Apteka) error {
+	ctx := context.Background()
+
+	user, err := lp.getUser(ctx, chat.Id)
+	if err != nil {
+		return err
+	}
+
+	// the id comes from the form and would let it overwrite another apteka,
+	// a new record is always created
+	apteka.ID = primitive.NilObjectID
+	apteka.OwnerIDs = []int64{chat.Id}
+	apteka.CreatedAt = time.Now()
+	switch {
+	case user != nil && user.IsChainManager():
+		// a chain manager adds a branch of the chain and stays the manager
+		apteka.ChainID = user.ChainId
+		apteka.OwnerIDs = nil
+	case user == nil || !user.IsAdmin():
+		// anyone can open the form, the creator becomes the pharmacist
+		// only after an admin approves the apteka
+		apteka.Pending = true
+	}
+	res, err := lp.mongoClient.Database(db.Database).Collection("aptekas").InsertOne(ctx, apteka)
 	if err != nil {
 		return err
 	}
+	apteka.ID = res.InsertedID.(primitive.ObjectID)
+	log.Printf("[ChatId=%d] Created apteka %s, pending: %t", chat.Id, apteka.ID.Hex(), apteka.Pending)
 
-	lp.sendText(chat.Id, "Объявление успешно создано!")
+	lang := lp.lang(chat.Id)
+	if apteka.Pending {
+		go lp.sendAptekaToReview(apteka)
+		return lp.sendText(chat.Id, i18n.T(lang, TextAptekaPending)+"\n\n"+aptekaCard(lang, apteka))
+	}
+
+	lp.reloadSearch()
+	return lp.sendText(chat.Id, i18n.T(lang, TextAptekaCreated)+"\n\n"+aptekaCard(lang, apteka)+"\n\n"+i18n.T(lang, TextAptekaInvite))
+}
 
-	return nil
+func aptekaCard(lang i18n.Lang, apteka *model.Apteka) string {
+	return EmojiHospital + " " + apteka.Name + "\n" +
+		EmojiPin + " " + apteka.Address + "\n" +
+		EmojiPhone + " " + phone.Format(apteka.Phone) + "\n" +
+		EmojiClock + " " + apteka.WorkHours.String() + "\n" +
+		workStatus(lang, apteka.WorkHours, time.Now())
 }
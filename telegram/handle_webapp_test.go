@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+)
+
+func TestValidateWebApp(t *testing.T) {
+	const house = `"city": "Бишкек", "address": "ул. Ленина 1", "phone": "0555 123 456", "room_count": 2, "price": 30000`
+	const apteka = `"type": "apteka", "name": "Неман", "address": "ул. Ленина 1", "phone": "+996555123456"`
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   []model.FieldError
+	}{
+		{"house", `{` + house + `}`, http.StatusOK, []model.FieldError{}},
+		{"apteka", `{` + apteka + `, "work_hours": "Пн-Пт 9:00-21:00"}`, http.StatusOK, []model.FieldError{}},
+		{"missing field", `{"type": "house", "address": "ул. Ленина 1", "phone": "0555123456", "room_count": 1}`, http.StatusUnprocessableEntity,
+			[]model.FieldError{{Field: "city", Code: model.CodeRequired}}},
+		{"bounds", `{"city": "Ош", "address": "ул", "phone": "0555123456", "room_count": 21}`, http.StatusUnprocessableEntity,
+			[]model.FieldError{
+				{Field: "address", Code: model.CodeTooShort, Params: []int{5}},
+				{Field: "room_count", Code: model.CodeRange, Params: []int{1, 20}},
+			}},
+		{"bad phone", `{` + strings.Replace(house, "0555 123 456", "12345", 1) + `}`, http.StatusUnprocessableEntity,
+			[]model.FieldError{{Field: "phone", Code: model.CodeFormat}}},
+		{"bad hours", `{` + apteka + `, "work_hours": "по звонку"}`, http.StatusUnprocessableEntity,
+			[]model.FieldError{{Field: "work_hours", Code: model.CodeFormat}}},
+		{"no hours", `{` + apteka + `}`, http.StatusUnprocessableEntity,
+			[]model.FieldError{{Field: "work_hours", Code: model.CodeRequired}}},
+		{"wrong type", `{` + strings.Replace(house, "30000", `"30000"`, 1) + `}`, http.StatusUnprocessableEntity,
+			[]model.FieldError{{Field: "price", Code: model.CodeType}}},
+		{"malformed", `{"city": `, http.StatusBadRequest, nil},
+		{"unknown type", `{"type": "car"}`, http.StatusBadRequest, nil},
+	}
+	lp := &LongPoll{}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		lp.ValidateWebApp(rec, httptest.NewRequest(http.MethodPost, "/webapp/validate", strings.NewReader(tt.body)))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+			continue
+		}
+		if tt.want == nil {
+			continue
+		}
+		var got ValidationPayload
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Errorf("%s: could not decode the response: %v", tt.name, err)
+			continue
+		}
+		fields := []model.FieldError{}
+		for _, e := range got.Errors {
+			fields = append(fields, e.FieldError)
+			if e.Message != TextFieldError(i18n.Default, e.FieldError) {
+				t.Errorf("%s: message %q of %s", tt.name, e.Message, e.Field)
+			}
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%s: errors %+v, want %+v", tt.name, fields, tt.want)
+		}
+	}
+}
+
+func TestRequestLang(t *testing.T) {
+	tests := []struct {
+		target, header string
+		want           i18n.Lang
+	}{
+		{"/webapp/validate", "", i18n.Default},
+		{"/webapp/validate?lang=ky", "en", i18n.KY},
+		{"/webapp/validate?lang=de", "de-DE,kk;q=0.9,en;q=0.8", i18n.KK},
+		{"/houses?lang=en-GB", "", i18n.EN},
+		{"/houses", "en-US,en;q=0.9", i18n.EN},
+		{"/houses", "de, fr", i18n.Default},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.Header.Set("Accept-Language", tt.header)
+		if got := requestLang(r); got != tt.want {
+			t.Errorf("requestLang(%s, %q) = %s, want %s", tt.target, tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSearchHousesErrorLang(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&LongPoll{}).SearchHouses(rec, httptest.NewRequest(http.MethodGet, "/houses?lang=en&sort=cheap&rooms_min=3&rooms_max=2", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var got ValidationPayload
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, e := range got.Errors {
+		messages = append(messages, e.Message)
+	}
+	want := []string{"Rooms from: from 0 to 2", "Sort: one of new, price, -price, rooms"}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("messages %q, want %q", messages, want)
+	}
+}
+
+func TestTextFieldError(t *testing.T) {
+	tests := []struct {
+		lang i18n.Lang
+		f    model.FieldError
+		want string
+	}{
+		{i18n.RU, model.FieldError{Field: "city", Code: model.CodeRequired}, "Город: обязательное поле"},
+		{i18n.RU, model.FieldError{Field: "name", Code: model.CodeTooLong, Params: []int{100}}, "Название: не длиннее 100 символов"},
+		{i18n.EN, model.FieldError{Field: "price", Code: model.CodeRange, Params: []int{0, 100}}, "Price: from 0 to 100"},
+		// the phone and the work hours explain their format
+		{i18n.RU, model.FieldError{Field: "phone", Code: model.CodeFormat}, "Телефон: номер Кыргызстана, Казахстана или России, например 0555 123 456"},
+		{i18n.EN, model.FieldError{Field: "work_hours", Code: model.CodeInvalid}, "Opening hours: unknown timezone or wrong date"},
+		{i18n.EN, model.FieldError{Field: "address", Code: model.CodeFormat}, "Address: wrong format"},
+		// fields without a label are named as they are
+		{i18n.RU, model.FieldError{Field: "id", Code: model.CodeType}, "id: неверный тип значения"},
+	}
+	for _, tt := range tests {
+		if got := TextFieldError(tt.lang, tt.f); got != tt.want {
+			t.Errorf("TextFieldError(%s, %v) = %q, want %q", tt.lang, &tt.f, got, tt.want)
+		}
+	}
+}
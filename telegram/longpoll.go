@@ -7,9 +7,15 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
-	"github.com/google/uuid"
 	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/blob"
 	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/imaging"
+	"github.com/oybek/jethouse/medicine"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/search"
+	"github.com/oybek/jethouse/voice"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -17,36 +23,79 @@ import (
 	"log"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
 )
 
 type LongPoll struct {
-	bot           *gotgbot.Bot
-	mongoClient   *mongo.Client
-	openaiClient  *openai.Client
-	photoCache    *ttlcache.Cache[int64, []uuid.UUID]
-	prevProcesses map[int64]string
+	bot            *gotgbot.Bot
+	mongoClient    *mongo.Client
+	openaiClient   *openai.Client
+	photoCache     *ttlcache.Cache[int64, []model.Photo]
+	blobStore      blob.Store
+	imageProcessor *imaging.Processor
+	prevProcesses  map[int64]string
+	extractor      *medicine.Extractor
+	catalog        atomic.Pointer[medicine.Catalog]
+	engine         *search.Engine
+	transcriber    *voice.Transcriber
+	speaker        *voice.Speaker
+	pendingStock   *ttlcache.Cache[string, pendingStock]
+	houseSearches  *ttlcache.Cache[string, houseQuery]
+	// listings being edited through the web app
+	houseEdits *ttlcache.Cache[int64, primitive.ObjectID]
+	// photos replacing the ones of a listing, kept apart from a new listing's photos
+	listingPhotos *ttlcache.Cache[int64, listingPhotos]
+	// listings the moderators write a reject reason for, by moderator
+	rejectReasons *ttlcache.Cache[int64, pendingReject]
+	// guards adding to photoCache, album photos arrive concurrently
+	photoMu sync.Mutex
+	// house alerts sent to the user in the current window
+	alertCounts *ttlcache.Cache[int64, int]
+	// guards counting in alertCounts, listings are announced concurrently
+	alertMu sync.Mutex
+	// languages of the users seen recently
+	languages *ttlcache.Cache[int64, i18n.Lang]
+	// stock older than this is considered stale
+	inventoryFreshness time.Duration
+	// answer voice messages in GPT sessions with synthesized speech
+	voiceReplies bool
+	// group where new listings are moderated, admins moderate if it is zero
+	moderatorsChatId int64
+	// web app editing the listings, listings are not editable if it is empty
+	houseWebAppUrl string
+	// long polling or webhook
+	updates    UpdatesConfig
+	dispatcher *ext.Dispatcher
+	updater    *ext.Updater
 }
 
-func NewLongPoll(
-	bot *gotgbot.Bot,
-	mongoClient *mongo.Client,
-	openaiClient *openai.Client,
-	photoCache *ttlcache.Cache[int64, []uuid.UUID],
-) *LongPoll {
-	return &LongPoll{
-		bot:          bot,
-		mongoClient:  mongoClient,
-		openaiClient: openaiClient,
-		photoCache:   photoCache,
-	}
+// Config is what the bot is built from
+type Config struct {
+	Bot          *gotgbot.Bot
+	MongoClient  *mongo.Client
+	OpenAIClient *openai.Client
+	// PhotoCache keeps the photos of a listing until it is submitted
+	PhotoCache     *ttlcache.Cache[int64, []model.Photo]
+	BlobStore      blob.Store
+	ImageProcessor *imaging.Processor
+	// InventoryFreshness is how long uploaded stock is considered fresh
+	InventoryFreshness time.Duration
+	// VoiceReplies answers voice messages in GPT sessions with synthesized speech
+	VoiceReplies bool
+	// ModeratorsChatId is the group moderating new listings, admins moderate if it is zero
+	ModeratorsChatId int64
+	// HouseWebAppUrl is the web app editing the listings, listings are not editable if it is empty
+	HouseWebAppUrl string
+	// Updates are received by long polling or by webhook
+	Updates UpdatesConfig
 }
 
-const createAptekaWebAppUrl = "https://wolfrepos.github.io/apteka/create/index.html"
-
-func (lp *LongPoll) Run() {
+func NewLongPoll(cfg Config) *LongPoll {
+	// the updater is created here so that the webhook handler can be mounted before Run
 	dispatcher := ext.NewDispatcher(&ext.DispatcherOpts{
 		Error: func(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherAction {
 			log.Println("an error occurred while handling update:", err.Error())
@@ -54,13 +103,50 @@ func (lp *LongPoll) Run() {
 		},
 		MaxRoutines: ext.DefaultMaxRoutines,
 	})
-	updater := ext.NewUpdater(dispatcher, nil)
+	return &LongPoll{
+		bot:            cfg.Bot,
+		mongoClient:    cfg.MongoClient,
+		openaiClient:   cfg.OpenAIClient,
+		photoCache:     cfg.PhotoCache,
+		blobStore:      cfg.BlobStore,
+		imageProcessor: cfg.ImageProcessor,
+		extractor:      medicine.NewExtractor(cfg.OpenAIClient),
+		engine:         search.NewEngine(cfg.MongoClient.Database(db.Database)),
+		transcriber:    voice.NewTranscriber(cfg.OpenAIClient),
+		speaker:        voice.NewSpeaker(cfg.OpenAIClient),
+		pendingStock:   ttlcache.New(ttlcache.WithTTL[string, pendingStock](10*time.Minute), ttlcache.WithCapacity[string, pendingStock](pendingStockMax)),
+		houseSearches:  ttlcache.New(ttlcache.WithTTL[string, houseQuery](time.Hour), ttlcache.WithCapacity[string, houseQuery](houseSearchesMax)),
+		houseEdits:     ttlcache.New(ttlcache.WithTTL[int64, primitive.ObjectID](time.Hour)),
+		listingPhotos:  ttlcache.New(ttlcache.WithTTL[int64, listingPhotos](10*time.Minute), ttlcache.WithDisableTouchOnHit[int64, listingPhotos]()),
+		rejectReasons:  ttlcache.New(ttlcache.WithTTL[int64, pendingReject](time.Hour)),
+		alertCounts:    ttlcache.New(ttlcache.WithTTL[int64, int](houseAlertWindow)),
+		languages:      ttlcache.New(ttlcache.WithTTL[int64, i18n.Lang](time.Hour)),
+
+		inventoryFreshness: cfg.InventoryFreshness,
+		voiceReplies:       cfg.VoiceReplies,
+		moderatorsChatId:   cfg.ModeratorsChatId,
+		houseWebAppUrl:     cfg.HouseWebAppUrl,
+		updates:            cfg.Updates,
+		dispatcher:         dispatcher,
+		updater:            ext.NewUpdater(dispatcher, nil),
+	}
+}
+
+const createAptekaWebAppUrl = "https://wolfrepos.github.io/apteka/create/index.html"
+
+func (lp *LongPoll) Run() {
+	dispatcher := lp.dispatcher
 
 	// Setup handlers
+	dispatcher.AddHandlerToGroup(languageHandler{lp: lp}, -1)
 	dispatcher.AddHandler(handlers.NewMessage(
 		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/start111") },
 		lp.handleStartSession,
 	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/start "+invitePayloadPrefix) },
+		lp.handleStartInvite,
+	))
 	dispatcher.AddHandler(handlers.NewMessage(
 		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/buy") },
 		lp.handleBuySubscription,
@@ -89,6 +175,47 @@ func (lp *LongPoll) Run() {
 		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, "prompt_") },
 		lp.handlePromptSelection,
 	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, "invite_") },
+		lp.handleInviteCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, "stock_") },
+		lp.handleStockCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, suggestionPrefix) },
+		lp.handleSuggestionCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, housePrefix) },
+		lp.handleHouseCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, houseSearchPrefix) },
+		lp.handleHouseSearchCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, listingPrefix) },
+		lp.handleListingCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, alertPrefix) },
+		lp.handleAlertCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, moderationPrefix) },
+		lp.handleModerationCallback,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(lp.isRejectReason, lp.handleRejectReason))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, aptekaReviewPrefix) },
+		lp.handleAptekaReviewCallback,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, languagePrefix) },
+		lp.handleLanguageCallback,
+	))
 	dispatcher.AddHandler(handlers.NewMessage(
 		func(msg *gotgbot.Message) bool {
 			return true //
@@ -100,6 +227,42 @@ func (lp *LongPoll) Run() {
 		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/create_apteka") },
 		lp.handleCreateApteka,
 	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/invite") },
+		lp.handleInvite,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/create_chain") },
+		lp.handleCreateChain,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/my_aptekas") },
+		lp.handleMyAptekas,
+	))
+	dispatcher.AddHandler(handlers.NewCallback(
+		func(query *gotgbot.CallbackQuery) bool { return strings.HasPrefix(query.Data, myAptekasPrefix) },
+		lp.handleMyAptekasCallback,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/location") },
+		lp.handleLocationCommand,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/houses") },
+		lp.handleHouses,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/my_listings") },
+		lp.handleMyListings,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/alerts") },
+		lp.handleAlerts,
+	))
+	dispatcher.AddHandler(handlers.NewMessage(
+		func(msg *gotgbot.Message) bool { return strings.HasPrefix(msg.Text, "/language") },
+		lp.handleLanguage,
+	))
 	dispatcher.AddHandler(handlers.NewMessage(
 		func(msg *gotgbot.Message) bool { return msg.WebAppData != nil },
 		lp.handleWebAppData,
@@ -109,33 +272,33 @@ func (lp *LongPoll) Run() {
 	}, lp.handleWebAppData))
 	dispatcher.AddHandler(handlers.NewMessage(message.Text, lp.handleText))
 	dispatcher.AddHandler(handlers.NewMessage(message.Voice, lp.handleVoice))
+	dispatcher.AddHandler(handlers.NewMessage(message.Audio, lp.handleVoice))
+	dispatcher.AddHandler(handlers.NewMessage(message.VideoNote, lp.handleVoice))
 	dispatcher.AddHandler(handlers.NewMessage(message.Photo, lp.handlePhoto))
+	dispatcher.AddHandler(handlers.NewMessage(message.Document, lp.handleDocument))
+	dispatcher.AddHandler(handlers.NewMessage(message.Location, lp.handleLocation))
+	dispatcher.AddHandler(handlers.NewMessage(message.Contact, lp.handleContact))
+
+	go lp.refreshSearch()
+	go lp.remindStaleInventories()
 
 	// Start receiving updates.
-	err := updater.StartPolling(lp.bot, &ext.PollingOpts{
-		DropPendingUpdates: true,
-		GetUpdatesOpts: &gotgbot.GetUpdatesOpts{
-			Timeout: 9,
-			RequestOpts: &gotgbot.RequestOpts{
-				Timeout: time.Second * 10,
-			},
-		},
-	})
-	if err != nil {
-		panic("failed to start polling: " + err.Error())
+	if err := lp.startUpdates(); err != nil {
+		panic("failed to start receiving updates: " + err.Error())
 	}
 
-	// Setup commands
-	lp.bot.SetMyCommands(
-		[]gotgbot.BotCommand{
-			{Command: "create_apteka", Description: "Создать аптеку"},
-		}, nil,
-	)
+	// Setup commands, clients with other languages get the default ones
+	lp.bot.SetMyCommands(botCommands(i18n.Default), nil)
+	for _, lang := range i18n.Langs {
+		if lang != i18n.Default {
+			lp.bot.SetMyCommands(botCommands(lang), &gotgbot.SetMyCommandsOpts{LanguageCode: string(lang)})
+		}
+	}
 
 	log.Printf("%s has been started...\n", lp.bot.User.Username)
 
 	// Idle, to keep updates coming in, and avoid bot stopping.
-	updater.Idle()
+	lp.updater.Idle()
 }
 
 func (lp *LongPoll) handleStartSession(b *gotgbot.Bot, ctx *ext.Context) error {
@@ -148,17 +311,17 @@ func (lp *LongPoll) handleStartSession(b *gotgbot.Bot, ctx *ext.Context) error {
 	}
 
 	if !allowed {
-		return lp.sendText(userID, "Вы не можете запустить сессию. Возможно, у вас нет подписки.")
+		return lp.sendText(userID, lp.t(userID, TextSessionNotAllowed))
 	}
 
 	keyboard := [][]gotgbot.InlineKeyboardButton{
-		{gotgbot.InlineKeyboardButton{Text: "Тема 1", CallbackData: "prompt_1"}},
-		{gotgbot.InlineKeyboardButton{Text: "Тема 2", CallbackData: "prompt_2"}},
-		{gotgbot.InlineKeyboardButton{Text: "Тема 3", CallbackData: "prompt_3"}},
+		{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextSessionTopic, 1), CallbackData: "prompt_1"}},
+		{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextSessionTopic, 2), CallbackData: "prompt_2"}},
+		{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextSessionTopic, 3), CallbackData: "prompt_3"}},
 	}
 
 	// Отправляем сообщение с кнопками сразу после /start111
-	_, err = b.SendMessage(userID, "Выберите тему:", &gotgbot.SendMessageOpts{
+	_, err = b.SendMessage(userID, lp.t(userID, TextSessionChooseTopic), &gotgbot.SendMessageOpts{
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
 	})
 	if err != nil {
@@ -170,7 +333,7 @@ func (lp *LongPoll) handleStartSession(b *gotgbot.Bot, ctx *ext.Context) error {
 	_, _, err = lp.getOrCreateSession(userID)
 	if err != nil {
 		log.Println("Ошибка при создании сессии:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 
@@ -185,7 +348,7 @@ func (lp *LongPoll) handlerRequestSessionFeedback(b *gotgbot.Bot, ctx *ext.Conte
 		return nil
 	}
 
-	msg := "Оцените сессию от 1 до 5:"
+	msg := lp.t(userID, TextSessionRate)
 	buttons := [][]gotgbot.InlineKeyboardButton{
 		{
 			{Text: "1", CallbackData: fmt.Sprintf("feedback_%s_1", sessionID)},
@@ -279,7 +442,7 @@ func (lp *LongPoll) handlePromptSelection(b *gotgbot.Bot, ctx *ext.Context) erro
 	sessionID, _, err := lp.getOrCreateSession(userID)
 	if err != nil {
 		log.Println("Ошибка при получении sessionID:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 
@@ -330,7 +493,7 @@ func (lp *LongPoll) handlePromptSelection(b *gotgbot.Bot, ctx *ext.Context) erro
 	promptText, err := lp.getPromptFromDB(promptID)
 	if err != nil {
 		log.Println("Ошибка при получении промта:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 	prompt := []openai.ChatCompletionMessage{
@@ -351,7 +514,7 @@ func (lp *LongPoll) handlePromptSelection(b *gotgbot.Bot, ctx *ext.Context) erro
 	response, err := lp.sendToChatGPT(userID, prompt)
 	if err != nil {
 		log.Println("Ошибка при запросе к GPT:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 
@@ -375,9 +538,11 @@ func (lp *LongPoll) handlePromptSelection(b *gotgbot.Bot, ctx *ext.Context) erro
 func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 	userID := ctx.EffectiveMessage.From.Id
 	userText := ctx.EffectiveMessage.Text
+	sp := messageSpeech(ctx.EffectiveMessage)
+	photo := largestPhoto(ctx.EffectiveMessage)
 
-	if userText == "" {
-		return nil
+	if userText == "" && sp == nil && photo == nil {
+		return ext.ContinueGroups
 	}
 
 	// Проверяем процесс пользователя
@@ -386,10 +551,34 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 		log.Println("Ошибка при получении процесса пользователя:", err)
 		return err
 	}
+
+	// Вне диалога сообщение обрабатывают следующие хендлеры (поиск аптек, вебапп)
+	if userProcess != "support" && userProcess != "feedback" && userProcess != "in_session" {
+		return ext.ContinueGroups
+	}
+
+	// Фото вне сессии с GPT относятся к объявлению о доме
+	var attachments []dialogAttachment
+	if photo != nil {
+		if userProcess != "in_session" {
+			return ext.ContinueGroups
+		}
+		userText = ctx.EffectiveMessage.Caption
+		attachments = append(attachments, dialogAttachment{Type: attachmentImage, FileId: photo.FileId})
+	}
+
+	// Голосовое в диалоге распознаем и дальше обрабатываем как текст
+	if sp != nil {
+		userText, err = lp.transcribeDialogSpeech(userID, sp)
+		if err != nil || userText == "" {
+			return err
+		}
+	}
+
 	sessionID, _, err := lp.getOrCreateSession(userID)
 	if err != nil {
 		log.Println("Ошибка при получении sessionID:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 
@@ -401,7 +590,7 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 			return err
 		}
 
-		_, _ = b.SendMessage(userID, "Ваше сообщение отправлено в поддержку.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextSupportSent), nil)
 
 		// Обновляем процесс с support на none  у текущего юзера
 		err = lp.updateUserProcess(userID, "none")
@@ -426,7 +615,7 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 			return err
 		}
 
-		_, _ = b.SendMessage(userID, "Спасибо, ваш отзыв очень важен.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextFeedbackThanks), nil)
 
 		// Обновляем процесс с feedback на none  у текущего юзера
 		err = lp.updateUserProcess(userID, "none")
@@ -449,25 +638,25 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 	err = collection.FindOne(context.TODO(), bson.M{
 		"user_id":   userID,
 		"is_closed": false, //
-	}, options.FindOne().SetSort(bson.D{{"created_at", -1}})).Decode(&lastSession)
+	}, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})).Decode(&lastSession)
 
 	if err != nil && err != mongo.ErrNoDocuments {
 		log.Println("Ошибка при проверке последней сессии:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 
 	// === 1. Блокируем сообщения, если юзер не выбрал промт ===
 	if lastSession != nil {
 		if waiting, ok := lastSession["waiting_for_prompt"].(bool); ok && waiting {
-			_, _ = b.SendMessage(userID, "Сначала выберите тему, затем можете писать сообщения.", nil)
+			_, _ = b.SendMessage(userID, lp.t(userID, TextSessionTopicFirst), nil)
 			return nil
 		}
 	}
 	// === 2. Блокируем сообщения, если последняя сессия закрыта ===
 	if lastSession != nil {
 		if closed, ok := lastSession["is_closed"].(bool); ok && closed {
-			_, _ = b.SendMessage(userID, "Последняя сессия закрыта. Вы можете начать новый диалог.", nil)
+			_, _ = b.SendMessage(userID, lp.t(userID, TextSessionLastClosed), nil)
 			return nil
 		}
 	}
@@ -475,18 +664,18 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 	sessionID, existingSession, err := lp.getOrCreateSession(userID)
 	if err != nil {
 		log.Println("Ошибка при получении sessionID:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 
 	if existingSession["waiting_for_prompt"].(bool) {
-		_, _ = b.SendMessage(userID, "Сначала выберите тему.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextSessionTopicNeeded), nil)
 		return nil
 	}
 
 	// Если сессия закрыта, не даем писать
 	if existingSession["is_closed"].(bool) {
-		_, _ = b.SendMessage(userID, "Сессия закрыта. Вы можете начать новый диалог.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextSessionClosed), nil)
 		return nil
 	}
 
@@ -516,17 +705,17 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 			log.Println("Ошибка при обновлении процесса с in_session на none:", err)
 		}
 
-		_, _ = b.SendMessage(userID, "Лимит сообщений исчерпан. Вы можете начать новый диалог.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextSessionLimit), nil)
 
 		return lp.handleEndOfSession(b, ctx)
 
 	}
 
 	// Сохраняем сообщение пользователя в коллекцию dialogues
-	err = lp.saveUserMessage(userID, sessionID, userText)
+	err = lp.saveUserMessage(userID, sessionID, userText, attachments...)
 	if err != nil {
 		log.Println("Ошибка при сохранении текста пользователя в коллекцию dialogues:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 
@@ -539,7 +728,7 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 	messages, err := lp.getAllMessages(userID)
 	if err != nil {
 		log.Println("Ошибка при получении истории сообщений:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 	log.Println("История сообщений перед отправкой в GPT:", messages)
@@ -550,7 +739,7 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 	response, err := lp.sendToChatGPT(userID, messages)
 	if err != nil {
 		log.Println("Ошибка при запросе к GPT:", err)
-		_, _ = b.SendMessage(userID, "Ошибка сервера, попробуйте позже.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextServerError), nil)
 		return err
 	}
 	_, err = b.SendMessage(userID, response, nil)
@@ -558,6 +747,10 @@ func (lp *LongPoll) handleUserMessage(b *gotgbot.Bot, ctx *ext.Context) error {
 		log.Println("Ошибка при отправке сообщения:", err)
 		return err
 	}
+	// На голосовое отвечаем и голосом, если это включено
+	if sp != nil && lp.voiceReplies {
+		lp.sendSpeech(userID, response)
+	}
 	// Сохраняем ответ GPT в диалог
 	err = lp.saveMessageToSession(userID, sessionID, response)
 	if err != nil {
@@ -579,7 +772,7 @@ func (lp *LongPoll) handleBuySubscription(b *gotgbot.Bot, ctx *ext.Context) erro
 	}
 
 	if user == nil {
-		_, _ = b.SendMessage(userID, "Ошибка: пользователь не найден.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextUserNotFound), nil)
 		return nil
 	}
 	// Читаем текущую подписку
@@ -596,40 +789,37 @@ func (lp *LongPoll) handleBuySubscription(b *gotgbot.Bot, ctx *ext.Context) erro
 
 	switch currentPlan {
 	case "premium":
-		messageToUser = fmt.Sprintf("У вас уже активен тариф 'Premium'. Он истекает: %s.\n"+
-			"Вы сможете продлить подписку после окончания.",
-			subscriptionEnd.Time().Format("02.01.2006"))
+		messageToUser = lp.t(userID, TextPlanPremiumActive, subscriptionEnd.Time().Format("02.01.2006"))
 	case "standard":
-		messageToUser = "У вас активен тариф 'Standard'. Вы можете перейти на 'Premium'."
+		messageToUser = lp.t(userID, TextPlanStandardActive)
 		keyboard = [][]gotgbot.InlineKeyboardButton{
-			{gotgbot.InlineKeyboardButton{Text: "Купить Premium", CallbackData: "sub_premium"}},
+			{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanBuy, "Premium"), CallbackData: "sub_premium"}},
 		}
 	case "basic":
-		messageToUser = "У вас активен тариф 'Basic'. Вы можете перейти на 'Standard' или 'Premium'."
+		messageToUser = lp.t(userID, TextPlanBasicActive)
 		keyboard = [][]gotgbot.InlineKeyboardButton{
-			{gotgbot.InlineKeyboardButton{Text: "Купить Standard", CallbackData: "sub_standard"}},
-			{gotgbot.InlineKeyboardButton{Text: "Купить Premium", CallbackData: "sub_premium"}},
+			{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanBuy, "Standard"), CallbackData: "sub_standard"}},
+			{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanBuy, "Premium"), CallbackData: "sub_premium"}},
 		}
 	case "trial":
 		if isExpired || sessionLeft == 0 {
 			log.Println("⚠Пробный период истёк. Показываем выбор тарифов.")
-			messageToUser = "Ваш пробный период истёк. Выберите тарифный план:"
+			messageToUser = lp.t(userID, TextPlanTrialExpired)
 			keyboard = [][]gotgbot.InlineKeyboardButton{
-				{gotgbot.InlineKeyboardButton{Text: "Basic (30 дней, 30 сессий)", CallbackData: "sub_basic"}},
-				{gotgbot.InlineKeyboardButton{Text: "Standard (60 дней, 60 сессий)", CallbackData: "sub_standard"}},
-				{gotgbot.InlineKeyboardButton{Text: "Premium (90 дней, безлимит)", CallbackData: "sub_premium"}},
+				{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanBasic), CallbackData: "sub_basic"}},
+				{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanStandard), CallbackData: "sub_standard"}},
+				{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanPremium), CallbackData: "sub_premium"}},
 			}
 		} else {
-			messageToUser = fmt.Sprintf("Вы используете пробный тариф. Он истекает: %s.",
-				subscriptionEnd.Time().Format("02.01.2006"))
+			messageToUser = lp.t(userID, TextPlanTrialActive, subscriptionEnd.Time().Format("02.01.2006"))
 		}
 	default:
 		log.Println("У пользователя нет активной подписки. Показываем все тарифы.")
-		messageToUser = "У вас нет активной подписки. Выберите тарифный план:"
+		messageToUser = lp.t(userID, TextPlanNone)
 		keyboard = [][]gotgbot.InlineKeyboardButton{
-			{gotgbot.InlineKeyboardButton{Text: "Basic (30 дней, 30 сессий)", CallbackData: "sub_basic"}},
-			{gotgbot.InlineKeyboardButton{Text: "Standard (60 дней, 60 сессий)", CallbackData: "sub_standard"}},
-			{gotgbot.InlineKeyboardButton{Text: "Premium (90 дней, безлимит)", CallbackData: "sub_premium"}},
+			{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanBasic), CallbackData: "sub_basic"}},
+			{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanStandard), CallbackData: "sub_standard"}},
+			{gotgbot.InlineKeyboardButton{Text: lp.t(userID, TextPlanPremium), CallbackData: "sub_premium"}},
 		}
 
 	}
@@ -662,7 +852,7 @@ func (lp *LongPoll) handleSubscriptionCallback(b *gotgbot.Bot, ctx *ext.Context)
 	case "sub_premium":
 		planName = "premium"
 	default:
-		_, _ = b.SendMessage(userID, "Некорректный выбор подписки.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextSubscriptionInvalid), nil)
 		return nil
 	}
 
@@ -670,7 +860,7 @@ func (lp *LongPoll) handleSubscriptionCallback(b *gotgbot.Bot, ctx *ext.Context)
 	err := lp.buySubscription(userID, planName)
 	if err != nil {
 		log.Println("Ошибка при покупке подписки:", err)
-		_, _ = b.SendMessage(userID, "Ошибка при оформлении подписки.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextSubscriptionError), nil)
 		return err
 	}
 
@@ -691,7 +881,7 @@ func (lp *LongPoll) handleSubscriptionCallback(b *gotgbot.Bot, ctx *ext.Context)
 	}
 
 	// Отвечаем пользователю
-	_, _ = b.SendMessage(userID, "Вы успешно оформили подписку: "+planName, nil)
+	_, _ = b.SendMessage(userID, lp.t(userID, TextSubscriptionDone, planName), nil)
 
 	return nil
 }
@@ -705,7 +895,7 @@ func (lp *LongPoll) handleEndOfSession(b *gotgbot.Bot, ctx *ext.Context) error {
 	if err != nil {
 		log.Println("[handleEndOfSession] Ошибка при получении или создании сессии:", err)
 		log.Println("Ошибка при получении или создании сессии:", err)
-		_, _ = b.SendMessage(userID, "У вас нет активной сессии.", nil)
+		_, _ = b.SendMessage(userID, lp.t(userID, TextSessionNone), nil)
 		return err
 	}
 
@@ -719,7 +909,7 @@ func (lp *LongPoll) handleEndOfSession(b *gotgbot.Bot, ctx *ext.Context) error {
 	}
 	log.Printf("[handleEndOfSession] Сессия sessionID=%s закрыта", sessionID)
 
-	_, _ = b.SendMessage(userID, "Сессия завершена. Вы можете начать новый диалог.", nil)
+	_, _ = b.SendMessage(userID, lp.t(userID, TextSessionFinished), nil)
 
 	return lp.handlerRequestSessionFeedback(b, ctx)
 }
@@ -732,7 +922,7 @@ func (lp *LongPoll) handleTechSupportCommand(b *gotgbot.Bot, ctx *ext.Context) e
 	if err != nil {
 		log.Println("Ошибка при установке процесса support:", err)
 	}
-	_, err = b.SendMessage(userID, "Отправьте ваше сообщение в поддержку:", nil)
+	_, err = b.SendMessage(userID, lp.t(userID, TextSupportPrompt), nil)
 	return err
 }
 
@@ -745,13 +935,17 @@ func (lp *LongPoll) handleBotFeedbackCommand(b *gotgbot.Bot, ctx *ext.Context) e
 		log.Println("Ошибка при установке процесса support:", err)
 	}
 
-	_, err = b.SendMessage(userID, "Отправьте обратную связь на бота:", nil)
+	_, err = b.SendMessage(userID, lp.t(userID, TextFeedbackPrompt), nil)
 	return err
 }
 
 func (lp *LongPoll) handleText(b *gotgbot.Bot, ctx *ext.Context) error {
 	chat := ctx.EffectiveMessage.Chat
-	return lp.sendText(chat.Id, TextDefault)
+	text := strings.TrimSpace(ctx.EffectiveMessage.Text)
+	if strings.HasPrefix(text, "/") {
+		return lp.sendText(chat.Id, lp.t(chat.Id, TextDefault))
+	}
+	return lp.searchByText(chat.Id, text)
 }
 
 func (lp *LongPoll) sendText(chatId int64, text string) error {
@@ -766,11 +960,11 @@ func (lp *LongPoll) handleCreateApteka(b *gotgbot.Bot, ctx *ext.Context) error {
 		ResizeKeyboard:  true,
 		Keyboard: [][]gotgbot.KeyboardButton{
 			{
-				{Text: "Создать аптеку", WebApp: &gotgbot.WebAppInfo{Url: createAptekaWebAppUrl}},
+				{Text: lp.t(chat.Id, ButtonCreateApteka), WebApp: &gotgbot.WebAppInfo{Url: createAptekaWebAppUrl}},
 			},
 		},
 	}
-	_, err := lp.bot.SendMessage(chat.Id, TextCreateApteka,
+	_, err := lp.bot.SendMessage(chat.Id, lp.t(chat.Id, TextCreateApteka),
 		&gotgbot.SendMessageOpts{ReplyMarkup: createAptekaKeyboard})
 	return err
 }
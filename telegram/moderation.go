@@ -0,0 +1,494 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// callback data is mod_<action>_<house id>, reasons are chosen with mod_r<index>_<house id>
+const moderationPrefix = "mod_"
+
+// a written reject reason is shown in the owner's card, it is kept short
+const rejectReasonMax = 500
+
+// pendingReject is the listing whose reject reason the moderator is writing
+type pendingReject struct {
+	houseID   primitive.ObjectID
+	chatId    int64
+	messageId int64
+}
+
+// automatic check flags shown to moderators
+const (
+	flagBannedWord       = "banned_word"
+	flagUnverifiedPhone  = "unverified_phone"
+	flagDuplicateAddress = "duplicate_address"
+	flagDuplicatePhone   = "duplicate_phone"
+)
+
+// stems of typical scam and prohibited listings, matched at the start of a
+// word so that "предоплата" is found but a stem inside another word is not
+var bannedWords = []string{"предоплат", "казино", "букмекер", "наркот", "закладк", "эскорт", "интим"}
+
+// documentCounter is the part of *mongo.Collection used by checkHouse
+type documentCounter interface {
+	CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error)
+}
+
+// submitHouse puts the listing to the moderation queue, it stays hidden until approved
+func (lp *LongPoll) submitHouse(ctx context.Context, house *model.House) error {
+	house.Flags = checkHouse(ctx, lp.mongoClient.Database(db.Database).Collection("houses"), house)
+	house.Status = model.HousePending
+	house.Active = false
+
+	_, err := lp.mongoClient.Database(db.Database).Collection("houses").UpdateOne(ctx,
+		bson.M{"_id": house.ID},
+		bson.M{
+			"$set":   bson.M{"status": model.HousePending, "active": false, "flags": house.Flags},
+			"$unset": bson.M{"reject_reason": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	lp.logModeration(ctx, model.ModerationEvent{
+		HouseID: house.ID,
+		ActorID: house.OwnerID,
+		Action:  model.ModerationSubmit,
+		Flags:   house.Flags,
+	})
+
+	go lp.sendToModerators(house)
+	return nil
+}
+
+// checkHouse looks for banned words, unconfirmed phones and listings that
+// duplicate other owners' ones, a failed duplicate check is logged and skipped
+func checkHouse(ctx context.Context, coll documentCounter, house *model.House) []string {
+	var flags []string
+
+	if hasBannedWord(house.City + " " + house.Address) {
+		flags = append(flags, flagBannedWord)
+	}
+
+	if !house.PhoneVerified {
+		flags = append(flags, flagUnverifiedPhone)
+	}
+
+	visible := bson.M{"$in": bson.A{model.HousePending, model.HouseApproved}}
+	exact := func(s string) primitive.Regex {
+		return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(s)) + "$", Options: "i"}
+	}
+
+	n, err := coll.CountDocuments(ctx, bson.M{
+		"_id":     bson.M{"$ne": house.ID},
+		"status":  visible,
+		"city":    exact(house.City),
+		"address": exact(house.Address),
+	})
+	if err != nil {
+		log.Printf("Could not check house %s address: %s", house.ID.Hex(), err.Error())
+	} else if n > 0 {
+		flags = append(flags, flagDuplicateAddress)
+	}
+
+	n, err = coll.CountDocuments(ctx, bson.M{
+		"owner_id": bson.M{"$ne": house.OwnerID},
+		"status":   visible,
+		"phone":    house.Phone,
+	})
+	if err != nil {
+		log.Printf("Could not check house %s phone: %s", house.ID.Hex(), err.Error())
+	} else if n > 0 {
+		flags = append(flags, flagDuplicatePhone)
+	}
+	return flags
+}
+
+func hasBannedWord(text string) bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		for _, stem := range bannedWords {
+			if strings.HasPrefix(word, stem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// moderatorChats is the moderators group, or admins when it is not configured
+func (lp *LongPoll) moderatorChats(ctx context.Context) ([]int64, error) {
+	if lp.moderatorsChatId != 0 {
+		return []int64{lp.moderatorsChatId}, nil
+	}
+
+	cursor, err := lp.mongoClient.Database(db.Database).Collection("users").
+		Find(ctx, bson.M{"role": model.RoleAdmin})
+	if err != nil {
+		return nil, err
+	}
+	var admins []model.User
+	if err = cursor.All(ctx, &admins); err != nil {
+		return nil, err
+	}
+	chats := make([]int64, 0, len(admins))
+	for _, admin := range admins {
+		chats = append(chats, admin.ChatId)
+	}
+	return chats, nil
+}
+
+func (lp *LongPoll) isModerator(ctx context.Context, query *gotgbot.CallbackQuery) (bool, error) {
+	if lp.moderatorsChatId != 0 && query.Message.GetChat().Id == lp.moderatorsChatId {
+		return true, nil
+	}
+	user, err := lp.getUser(ctx, query.From.Id)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsAdmin(), nil
+}
+
+func (lp *LongPoll) sendToModerators(house *model.House) {
+	chats, err := lp.moderatorChats(context.Background())
+	if err != nil {
+		log.Printf("Could not find moderators: %s", err.Error())
+		return
+	}
+	if len(chats) == 0 {
+		log.Printf("No moderators, house %s waits in the queue", house.ID.Hex())
+		return
+	}
+
+	for _, chatId := range chats {
+		if err := lp.sendHousePhotos(chatId, house.Photos); err != nil {
+			log.Printf("[ChatId=%d] Could not send house photos: %s", chatId, err.Error())
+		}
+		lang := lp.lang(chatId)
+		_, err := lp.bot.SendMessage(chatId, moderationCard(lang, house), &gotgbot.SendMessageOpts{
+			ReplyMarkup: moderationKeyboard(lang, house.ID),
+		})
+		if err != nil {
+			log.Printf("[ChatId=%d] Could not send house to moderation: %s", chatId, err.Error())
+		}
+	}
+}
+
+func moderationCard(lang i18n.Lang, house *model.House) string {
+	card := i18n.T(lang, TextModerationNew) + "\n\n" + houseCard(lang, house) + "\n" +
+		EmojiUser + " " + strconv.FormatInt(house.OwnerID, 10)
+	for _, flag := range house.Flags {
+		card += "\n⚠️ " + TextFlag(lang, flag)
+	}
+	return card
+}
+
+func moderationKeyboard(lang i18n.Lang, houseID primitive.ObjectID) gotgbot.InlineKeyboardMarkup {
+	id := houseID.Hex()
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+		{Text: i18n.T(lang, ButtonApprove), CallbackData: moderationPrefix + "ok_" + id},
+		{Text: i18n.T(lang, ButtonReject), CallbackData: moderationPrefix + "no_" + id},
+	}}}
+}
+
+func reasonsKeyboard(lang i18n.Lang, houseID primitive.ObjectID) gotgbot.InlineKeyboardMarkup {
+	id := houseID.Hex()
+	var keyboard [][]gotgbot.InlineKeyboardButton
+	for i, reason := range RejectReasons {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: i18n.T(lang, reason), CallbackData: moderationPrefix + "r" + strconv.Itoa(i) + "_" + id},
+		})
+	}
+	keyboard = append(keyboard,
+		[]gotgbot.InlineKeyboardButton{{Text: i18n.T(lang, ButtonRejectOther), CallbackData: moderationPrefix + "text_" + id}},
+		[]gotgbot.InlineKeyboardButton{{Text: i18n.T(lang, ButtonBack), CallbackData: moderationPrefix + "back_" + id}},
+	)
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+func (lp *LongPoll) handleModerationCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	moderatorId := query.From.Id
+	_, _ = query.Answer(b, nil)
+	if query.Message == nil {
+		return nil
+	}
+
+	bgCtx := context.Background()
+	allowed, err := lp.isModerator(bgCtx, query)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	action, hex, _ := strings.Cut(strings.TrimPrefix(query.Data, moderationPrefix), "_")
+	houseID, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil
+	}
+	chatId := query.Message.GetChat().Id
+	messageId := query.Message.GetMessageId()
+
+	switch action {
+	case "no", "back":
+		lang := lp.lang(chatId)
+		markup := moderationKeyboard(lang, houseID)
+		if action == "no" {
+			markup = reasonsKeyboard(lang, houseID)
+		}
+		_, _, err = b.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:      chatId,
+			MessageId:   messageId,
+			ReplyMarkup: markup,
+		})
+		return err
+	case "ok":
+		return lp.decideHouse(bgCtx, houseID, moderatorId, chatId, messageId, true, "")
+	case "text":
+		// the reason comes as a reply, in groups the bot sees only replies to its messages
+		lp.rejectReasons.Set(moderatorId, pendingReject{houseID: houseID, chatId: chatId, messageId: messageId}, ttlcache.DefaultTTL)
+		_, err = b.SendMessage(chatId, lp.t(chatId, TextModerationAskReason), &gotgbot.SendMessageOpts{
+			ReplyMarkup: gotgbot.ForceReply{ForceReply: true},
+		})
+		return err
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(action, "r"))
+	if !strings.HasPrefix(action, "r") || err != nil || index < 0 || index >= len(RejectReasons) {
+		return nil
+	}
+	return lp.decideHouse(bgCtx, houseID, moderatorId, chatId, messageId, false, string(RejectReasons[index]))
+}
+
+// isRejectReason tells whether the message is the reject reason the
+// moderator was asked to write in this chat
+func (lp *LongPoll) isRejectReason(msg *gotgbot.Message) bool {
+	if msg.From == nil || msg.ReplyToMessage == nil || strings.TrimSpace(msg.Text) == "" || strings.HasPrefix(msg.Text, "/") {
+		return false
+	}
+	kv := lp.rejectReasons.Get(msg.From.Id)
+	return kv != nil && kv.Value().chatId == msg.Chat.Id
+}
+
+// handleRejectReason rejects the listing with the reason written by the moderator
+func (lp *LongPoll) handleRejectReason(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	reason := strings.TrimSpace(msg.Text)
+	if utf8.RuneCountInString(reason) > rejectReasonMax {
+		return lp.sendText(msg.Chat.Id, lp.t(msg.Chat.Id, TextModerationReasonTooLong, rejectReasonMax))
+	}
+	kv, _ := lp.rejectReasons.GetAndDelete(msg.From.Id)
+	if kv == nil {
+		return nil
+	}
+	pending := kv.Value()
+	return lp.decideHouse(context.Background(), pending.houseID, msg.From.Id, pending.chatId, pending.messageId, false, reason)
+}
+
+// decideHouse approves or rejects the pending listing and tells the owner,
+// a chosen reason is stored as a catalog key and translated for every
+// reader, a written one is stored as is
+func (lp *LongPoll) decideHouse(ctx context.Context, houseID primitive.ObjectID, moderatorId, chatId, messageId int64, approve bool, reason string) error {
+	update := bson.M{"$set": bson.M{"status": model.HouseRejected, "reject_reason": reason}}
+	action := model.ModerationReject
+	if approve {
+		update = bson.M{"$set": bson.M{"status": model.HouseApproved, "active": true, "published_at": time.Now()}}
+		action = model.ModerationApprove
+	}
+
+	var house model.House
+	err := lp.mongoClient.Database(db.Database).Collection("houses").FindOneAndUpdate(ctx,
+		bson.M{"_id": houseID, "status": model.HousePending},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&house)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		_, _, err = lp.bot.EditMessageText(lp.t(chatId, TextModerationDone), &gotgbot.EditMessageTextOpts{
+			ChatId:    chatId,
+			MessageId: messageId,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	lp.logModeration(ctx, model.ModerationEvent{
+		HouseID: houseID,
+		ActorID: moderatorId,
+		Action:  action,
+		Reason:  reason,
+	})
+	log.Printf("[ChatId=%d] House %s: %s %s", moderatorId, houseID.Hex(), action, reason)
+
+	lang, ownerLang := lp.lang(chatId), lp.lang(house.OwnerID)
+	decision := i18n.T(lang, TextModerationApproved)
+	ownerText := i18n.T(ownerLang, TextHouseApproved) + "\n\n" + houseCard(ownerLang, &house)
+	if !approve {
+		decision = i18n.T(lang, TextModerationRejected, TextRejectReason(lang, reason))
+		ownerText = i18n.T(ownerLang, TextHouseRejected, TextRejectReason(ownerLang, reason)) + "\n\n" + houseCard(ownerLang, &house)
+	}
+	_, _, _ = lp.bot.EditMessageText(moderationCard(lang, &house)+"\n\n"+decision, &gotgbot.EditMessageTextOpts{
+		ChatId:    chatId,
+		MessageId: messageId,
+	})
+	if err := lp.sendText(house.OwnerID, ownerText); err != nil {
+		log.Printf("[ChatId=%d] Could not notify owner: %s", house.OwnerID, err.Error())
+	}
+
+	if approve {
+		go lp.notifyHouseAlerts(houseID)
+	}
+	return nil
+}
+
+// logModeration writes the audit log, a failed write does not stop the moderation
+func (lp *LongPoll) logModeration(ctx context.Context, event model.ModerationEvent) {
+	event.CreatedAt = time.Now()
+	_, err := lp.mongoClient.Database(db.Database).Collection("moderation_log").InsertOne(ctx, event)
+	if err != nil {
+		log.Printf("Could not write moderation log for house %s: %s", event.HouseID.Hex(), err.Error())
+	}
+}
+
+// callback data is aptrev_<ok|no>_<apteka id>
+const aptekaReviewPrefix = "aptrev_"
+
+// sendAptekaToReview asks the moderators to approve the apteka registered by a user
+func (lp *LongPoll) sendAptekaToReview(apteka *model.Apteka) {
+	chats, err := lp.moderatorChats(context.Background())
+	if err != nil {
+		log.Printf("Could not find moderators: %s", err.Error())
+		return
+	}
+	if len(chats) == 0 {
+		log.Printf("No moderators, apteka %s waits for review", apteka.ID.Hex())
+		return
+	}
+
+	id := apteka.ID.Hex()
+	for _, chatId := range chats {
+		lang := lp.lang(chatId)
+		_, err := lp.bot.SendMessage(chatId, aptekaReviewCard(lang, apteka), &gotgbot.SendMessageOpts{
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{Text: i18n.T(lang, ButtonApprove), CallbackData: aptekaReviewPrefix + "ok_" + id},
+				{Text: i18n.T(lang, ButtonReject), CallbackData: aptekaReviewPrefix + "no_" + id},
+			}}},
+		})
+		if err != nil {
+			log.Printf("[ChatId=%d] Could not send apteka to review: %s", chatId, err.Error())
+		}
+	}
+}
+
+func aptekaReviewCard(lang i18n.Lang, apteka *model.Apteka) string {
+	card := i18n.T(lang, TextAptekaReview) + "\n\n" + aptekaCard(lang, apteka)
+	for _, ownerId := range apteka.OwnerIDs {
+		card += "\n" + EmojiUser + " " + strconv.FormatInt(ownerId, 10)
+	}
+	return card
+}
+
+// handleAptekaReviewCallback approves the pending apteka and makes its
+// creator the pharmacist, a rejected apteka is deleted
+func (lp *LongPoll) handleAptekaReviewCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.CallbackQuery
+	moderatorId := query.From.Id
+	_, _ = query.Answer(b, nil)
+	if query.Message == nil {
+		return nil
+	}
+
+	bgCtx := context.Background()
+	allowed, err := lp.isModerator(bgCtx, query)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	action, hex, _ := strings.Cut(strings.TrimPrefix(query.Data, aptekaReviewPrefix), "_")
+	aptekaID, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil
+	}
+	chatId := query.Message.GetChat().Id
+	messageId := query.Message.GetMessageId()
+
+	coll := lp.mongoClient.Database(db.Database).Collection("aptekas")
+	pending := bson.M{"_id": aptekaID, "pending": true}
+	var apteka model.Apteka
+	switch action {
+	case "ok":
+		err = coll.FindOneAndUpdate(bgCtx, pending,
+			bson.M{"$unset": bson.M{"pending": ""}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&apteka)
+	case "no":
+		err = coll.FindOneAndDelete(bgCtx, pending).Decode(&apteka)
+	default:
+		return nil
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		_, _, err = b.EditMessageText(lp.t(chatId, TextAptekaReviewed), &gotgbot.EditMessageTextOpts{
+			ChatId:    chatId,
+			MessageId: messageId,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("[ChatId=%d] Apteka %s: %s", moderatorId, aptekaID.Hex(), action)
+
+	lang := lp.lang(chatId)
+	decision := i18n.T(lang, TextModerationApproved)
+	if action == "no" {
+		decision = i18n.T(lang, TextAptekaRefused)
+	}
+	_, _, _ = b.EditMessageText(aptekaReviewCard(lang, &apteka)+"\n\n"+decision, &gotgbot.EditMessageTextOpts{
+		ChatId:    chatId,
+		MessageId: messageId,
+	})
+
+	for _, ownerId := range apteka.OwnerIDs {
+		ownerLang := lp.lang(ownerId)
+		text := i18n.T(ownerLang, TextAptekaRejected) + "\n\n" + aptekaCard(ownerLang, &apteka)
+		if action == "ok" {
+			if err := lp.bindPharmacist(bgCtx, ownerId, apteka.ID); err != nil {
+				return err
+			}
+			text = i18n.T(ownerLang, TextAptekaApproved) + "\n\n" + aptekaCard(ownerLang, &apteka) + "\n\n" + i18n.T(ownerLang, TextAptekaUploadStock)
+		}
+		if err := lp.sendText(ownerId, text); err != nil {
+			log.Printf("[ChatId=%d] Could not notify apteka owner: %s", ownerId, err.Error())
+		}
+	}
+	if action == "ok" {
+		lp.reloadSearch()
+	}
+	return nil
+}
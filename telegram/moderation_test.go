@@ -0,0 +1,146 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// houseCounter answers the address and phone duplicate checks
+type houseCounter struct {
+	address, phone int64
+	err            error
+	filters        []bson.M
+}
+
+func (c *houseCounter) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	f := filter.(bson.M)
+	c.filters = append(c.filters, f)
+	if c.err != nil {
+		return 0, c.err
+	}
+	if _, ok := f["address"]; ok {
+		return c.address, nil
+	}
+	return c.phone, nil
+}
+
+func TestCheckHouse(t *testing.T) {
+	clean := model.House{City: "Бишкек", Address: "ул. Ставского 5", Phone: "+996555123456", PhoneVerified: true}
+	tests := []struct {
+		name    string
+		edit    func(h *model.House)
+		counter houseCounter
+		want    []string
+	}{
+		{"clean", func(h *model.House) {}, houseCounter{}, nil},
+		{"banned word", func(h *model.House) { h.Address = "Без предоплаты, ул. Ленина 1" }, houseCounter{}, []string{flagBannedWord}},
+		{"unverified phone", func(h *model.House) { h.PhoneVerified = false }, houseCounter{}, []string{flagUnverifiedPhone}},
+		{"duplicate address", func(h *model.House) {}, houseCounter{address: 1}, []string{flagDuplicateAddress}},
+		{"duplicate phone", func(h *model.House) {}, houseCounter{phone: 2}, []string{flagDuplicatePhone}},
+		{"all", func(h *model.House) { h.City = "Казино-Сити"; h.PhoneVerified = false }, houseCounter{address: 1, phone: 1},
+			[]string{flagBannedWord, flagUnverifiedPhone, flagDuplicateAddress, flagDuplicatePhone}},
+		{"failed checks", func(h *model.House) {}, houseCounter{address: 1, phone: 1, err: errors.New("timeout")}, nil},
+	}
+	for _, tt := range tests {
+		house := clean
+		house.ID = primitive.NewObjectID()
+		tt.edit(&house)
+		got := checkHouse(context.Background(), &tt.counter, &house)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: checkHouse() = %q, want %q", tt.name, got, tt.want)
+		}
+		if len(tt.counter.filters) != 2 {
+			t.Fatalf("%s: %d duplicate checks, want 2", tt.name, len(tt.counter.filters))
+		}
+		// only listings that are shown or about to be shown are duplicated
+		for _, f := range tt.counter.filters {
+			if f["status"] == nil {
+				t.Errorf("%s: filter %v does not check the status", tt.name, f)
+			}
+		}
+		if f := tt.counter.filters[0]; f["_id"].(bson.M)["$ne"] != house.ID {
+			t.Errorf("%s: address check %v does not skip the listing itself", tt.name, f)
+		}
+		if f := tt.counter.filters[1]; f["owner_id"].(bson.M)["$ne"] != house.OwnerID || f["phone"] != house.Phone {
+			t.Errorf("%s: phone check %v does not skip the owner's listings", tt.name, f)
+		}
+	}
+}
+
+func TestHasBannedWord(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Бишкек, ул. Ленина 1", false},
+		{"Нужна ПРЕДОПЛАТА", true},
+		{"без предоплаты!", true},
+		{"рядом казино", true},
+		{"эскорт-услуги", true},
+		{"интимные услуги", true},
+		// stems inside other words are not banned
+		{"доставка продуктов", false},
+		{"поставка мебели", false},
+		{"ставка аренды ниже рынка", false},
+		{"продам наркотики", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := hasBannedWord(tt.text); got != tt.want {
+			t.Errorf("hasBannedWord(%q) = %t, want %t", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTextRejectReason(t *testing.T) {
+	tests := []struct {
+		lang   i18n.Lang
+		reason string
+		want   string
+	}{
+		{i18n.EN, "reject_phone", "Wrong phone number"},
+		{i18n.RU, "reject_address", "Неполный или неверный адрес"},
+		// written reasons are not looked up in the catalog
+		{i18n.EN, "Фото не этой квартиры", "Фото не этой квартиры"},
+		{i18n.EN, "moderation_done", "moderation_done"},
+	}
+	for _, tt := range tests {
+		if got := TextRejectReason(tt.lang, tt.reason); got != tt.want {
+			t.Errorf("TextRejectReason(%s, %q) = %q, want %q", tt.lang, tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestIsRejectReason(t *testing.T) {
+	lp := &LongPoll{rejectReasons: ttlcache.New[int64, pendingReject]()}
+	lp.rejectReasons.Set(7, pendingReject{houseID: primitive.NewObjectID(), chatId: -100, messageId: 1}, ttlcache.DefaultTTL)
+
+	reply := &gotgbot.Message{MessageId: 2}
+	tests := []struct {
+		name string
+		msg  gotgbot.Message
+		want bool
+	}{
+		{"reply of the moderator", gotgbot.Message{From: &gotgbot.User{Id: 7}, Chat: gotgbot.Chat{Id: -100}, ReplyToMessage: reply, Text: "Фото не этой квартиры"}, true},
+		{"another moderator", gotgbot.Message{From: &gotgbot.User{Id: 8}, Chat: gotgbot.Chat{Id: -100}, ReplyToMessage: reply, Text: "Фото"}, false},
+		{"another chat", gotgbot.Message{From: &gotgbot.User{Id: 7}, Chat: gotgbot.Chat{Id: 7}, ReplyToMessage: reply, Text: "Фото"}, false},
+		{"not a reply", gotgbot.Message{From: &gotgbot.User{Id: 7}, Chat: gotgbot.Chat{Id: -100}, Text: "Фото"}, false},
+		{"command", gotgbot.Message{From: &gotgbot.User{Id: 7}, Chat: gotgbot.Chat{Id: -100}, ReplyToMessage: reply, Text: "/houses"}, false},
+		{"blank", gotgbot.Message{From: &gotgbot.User{Id: 7}, Chat: gotgbot.Chat{Id: -100}, ReplyToMessage: reply, Text: "  "}, false},
+	}
+	for _, tt := range tests {
+		if got := lp.isRejectReason(&tt.msg); got != tt.want {
+			t.Errorf("%s: isRejectReason = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package telegram
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/oybek/jethouse/db"
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const inventoryReminderInterval = time.Hour
+
+// a reminder about the same apteka is repeated not more often than once a day
+const inventoryRemindEvery = 24 * time.Hour
+
+// the chain manager is notified after this many ignored reminders
+const inventoryEscalateAfter = 3
+
+// remindStaleInventories periodically asks pharmacists to upload fresh stock
+func (lp *LongPoll) remindStaleInventories() {
+	for {
+		if err := lp.sendInventoryReminders(context.Background(), time.Now()); err != nil {
+			log.Printf("Could not send stock reminders: %s", err.Error())
+		}
+		time.Sleep(inventoryReminderInterval)
+	}
+}
+
+func (lp *LongPoll) sendInventoryReminders(ctx context.Context, now time.Time) error {
+	coll := lp.mongoClient.Database(db.Database).Collection("aptekas")
+	cursor, err := coll.Find(ctx, bson.M{
+		"pending": bson.M{"$ne": true},
+		"$or": bson.A{
+			bson.M{"reminded_at": bson.M{"$exists": false}},
+			bson.M{"reminded_at": bson.M{"$lte": now.Add(-inventoryRemindEvery)}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var aptekas []model.Apteka
+	if err = cursor.All(ctx, &aptekas); err != nil {
+		return err
+	}
+
+	for _, apteka := range aptekas {
+		if !reminderDue(&apteka, now, lp.inventoryFreshness) {
+			continue
+		}
+		if err := lp.remindApteka(ctx, &apteka, now); err != nil {
+			log.Printf("Could not remind apteka %s: %s", apteka.ID.Hex(), err.Error())
+		}
+	}
+	return nil
+}
+
+func (lp *LongPoll) remindApteka(ctx context.Context, apteka *model.Apteka, now time.Time) error {
+	apteka.MissedReminders++
+	apteka.RemindedAt = now
+	_, err := lp.mongoClient.Database(db.Database).Collection("aptekas").UpdateOne(ctx,
+		bson.M{"_id": apteka.ID},
+		bson.M{"$set": bson.M{"reminded_at": now, "missed_reminders": apteka.MissedReminders}},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, chatId := range apteka.OwnerIDs {
+		if err := lp.sendText(chatId, TextInventoryReminder(lp.lang(chatId), apteka, now)); err != nil {
+			log.Printf("[ChatId=%d] Could not send stock reminder: %s", chatId, err.Error())
+		}
+	}
+	log.Printf("Reminded %d pharmacists of apteka %s, missed %d", len(apteka.OwnerIDs), apteka.ID.Hex(), apteka.MissedReminders)
+
+	if !escalationDue(apteka) {
+		return nil
+	}
+	return lp.escalateStaleInventory(ctx, apteka, now)
+}
+
+// reminderDue tells whether the pharmacists are reminded now: the stock is
+// stale, a day has passed since the last reminder and the apteka is open
+func reminderDue(apteka *model.Apteka, now time.Time, freshness time.Duration) bool {
+	if !apteka.RemindedAt.IsZero() && now.Sub(apteka.RemindedAt) < inventoryRemindEvery {
+		return false
+	}
+	if !apteka.IsStale(now, freshness) {
+		return false
+	}
+	// pharmacists are reminded while they are at work
+	return apteka.WorkHours.IsZero() || apteka.WorkHours.IsOpenAt(now)
+}
+
+// escalationDue tells whether the chain managers are told after the reminder:
+// every inventoryEscalateAfter missed reminders, or every time if the branch
+// has no pharmacists to remind
+func escalationDue(apteka *model.Apteka) bool {
+	if apteka.ChainID.IsZero() {
+		return false
+	}
+	if len(apteka.OwnerIDs) == 0 {
+		return true
+	}
+	return apteka.MissedReminders > 0 && apteka.MissedReminders%inventoryEscalateAfter == 0
+}
+
+// escalateStaleInventory tells the chain managers about the branch ignoring reminders
+func (lp *LongPoll) escalateStaleInventory(ctx context.Context, apteka *model.Apteka, now time.Time) error {
+	var chain model.Chain
+	err := lp.mongoClient.Database(db.Database).Collection("chains").
+		FindOne(ctx, bson.M{"_id": apteka.ChainID}).
+		Decode(&chain)
+	if err != nil {
+		return err
+	}
+
+	for _, chatId := range chain.ManagerIDs {
+		if err := lp.sendText(chatId, TextInventoryEscalation(lp.lang(chatId), apteka, now)); err != nil {
+			log.Printf("[ChatId=%d] Could not send stock escalation: %s", chatId, err.Error())
+		}
+	}
+	return nil
+}
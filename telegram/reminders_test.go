@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oybek/jethouse/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReminderDue(t *testing.T) {
+	// 2025-01-06 is a Monday
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	const freshness = 72 * time.Hour
+	weekdays, err := model.ParseWorkHours("Пн-Пт 9:00-18:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weekdays.Timezone = "UTC"
+	weekends, err := model.ParseWorkHours("Сб-Вс 10:00-16:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weekends.Timezone = "UTC"
+
+	stale := now.Add(-freshness - time.Minute)
+	tests := []struct {
+		name   string
+		apteka model.Apteka
+		want   bool
+	}{
+		{"stale", model.Apteka{LastInventoryUpdate: stale}, true},
+		{"fresh", model.Apteka{LastInventoryUpdate: now.Add(-freshness)}, false},
+		{"never uploaded", model.Apteka{CreatedAt: stale}, true},
+		{"new", model.Apteka{CreatedAt: now.Add(-time.Hour)}, false},
+		{"no dates", model.Apteka{}, true},
+		{"reminded today", model.Apteka{LastInventoryUpdate: stale, RemindedAt: now.Add(-23 * time.Hour)}, false},
+		{"reminded a day ago", model.Apteka{LastInventoryUpdate: stale, RemindedAt: now.Add(-inventoryRemindEvery)}, true},
+		{"open", model.Apteka{LastInventoryUpdate: stale, WorkHours: weekdays}, true},
+		{"closed", model.Apteka{LastInventoryUpdate: stale, WorkHours: weekends}, false},
+	}
+	for _, tt := range tests {
+		if got := reminderDue(&tt.apteka, now, freshness); got != tt.want {
+			t.Errorf("%s: reminderDue() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEscalationDue(t *testing.T) {
+	chain := primitive.NewObjectID()
+	owners := []int64{1}
+	tests := []struct {
+		name   string
+		apteka model.Apteka
+		want   bool
+	}{
+		{"no reminders", model.Apteka{ChainID: chain, OwnerIDs: owners}, false},
+		{"first", model.Apteka{ChainID: chain, OwnerIDs: owners, MissedReminders: 1}, false},
+		{"second", model.Apteka{ChainID: chain, OwnerIDs: owners, MissedReminders: 2}, false},
+		{"third", model.Apteka{ChainID: chain, OwnerIDs: owners, MissedReminders: 3}, true},
+		{"fourth", model.Apteka{ChainID: chain, OwnerIDs: owners, MissedReminders: 4}, false},
+		{"sixth", model.Apteka{ChainID: chain, OwnerIDs: owners, MissedReminders: 6}, true},
+		{"no pharmacists", model.Apteka{ChainID: chain, MissedReminders: 1}, true},
+		{"no pharmacists, no reminders", model.Apteka{ChainID: chain}, true},
+		{"no chain", model.Apteka{OwnerIDs: owners, MissedReminders: 3}, false},
+		{"no chain, no pharmacists", model.Apteka{MissedReminders: 3}, false},
+	}
+	for _, tt := range tests {
+		if got := escalationDue(&tt.apteka); got != tt.want {
+			t.Errorf("%s: escalationDue() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,19 +1,349 @@
 package telegram
 
-const TextWhenOkStart = "Регистрация успешна! ✅\n\n" +
-	"Теперь наклейте QR код на Вашу машину так чтобы другие могли ее сканировать 😊\n\n" +
-	"Каждый раз когда кто-то будет сканировать QR код - вы будете получать вот такое уведомление:"
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-const TextWhenFailStart = "Регистрация неуспешна! 😔\n" +
-	"По данному QR уже была регистрация"
+	"github.com/oybek/jethouse/i18n"
+	"github.com/oybek/jethouse/model"
+	"github.com/oybek/jethouse/stock"
+)
 
-const TextMoveCar = "Вас просят переставить машину!"
+// keys of the bot texts, the texts themselves are in the i18n catalogs
 
-const TextDefault = "Какие лекарства Вы ищете? Просто запишите голосовое 😊"
-const TextTooLongVoice = "Вы отправили слишком длинное голосовое сообщение"
-const TextCreateApteka = "Чтобы создать аптеку нажмите кнопку ниже"
+const TextDefault i18n.Key = "default"
+const TextTooLongVoice i18n.Key = "voice_too_long"
+const TextVoiceNotRecognized i18n.Key = "voice_not_recognized"
+const TextYouSaid i18n.Key = "you_said"
+const TextSearching i18n.Key = "searching"
+
+const TextCreateApteka i18n.Key = "create_apteka"
+const TextNothingFound i18n.Key = "nothing_found"
+const TextNoMedicines i18n.Key = "no_medicines"
+const TextFoundAptekas i18n.Key = "found_aptekas"
+const TextDidYouMean i18n.Key = "did_you_mean"
+const TextSuggestionExpired i18n.Key = "suggestion_expired"
+const TextNotInCatalog i18n.Key = "not_in_catalog"
+const TextDistanceM i18n.Key = "distance_m"
+const TextDistanceKm i18n.Key = "distance_km"
+
+const TextNotPharmacist i18n.Key = "not_pharmacist"
+const TextStockFileTooBig i18n.Key = "stock_file_too_big"
+const TextStockProcessing i18n.Key = "stock_processing"
+const TextStockUnsupported i18n.Key = "stock_unsupported"
+const TextStockBroken i18n.Key = "stock_broken"
+const TextStockUpdated i18n.Key = "stock_updated"
+const TextStockImported i18n.Key = "stock_imported"
+const TextStockUnmatched i18n.Key = "stock_unmatched"
+const TextStockErrors i18n.Key = "stock_errors"
+const TextStockChooseApteka i18n.Key = "stock_choose_apteka"
+const TextStockExpired i18n.Key = "stock_expired"
+
+const TextAptekaInvite i18n.Key = "apteka_invite"
+const TextAptekaCreated i18n.Key = "apteka_created"
+const TextAptekaUploadStock i18n.Key = "apteka_upload_stock"
+const TextAptekaPending i18n.Key = "apteka_pending"
+const TextAptekaReview i18n.Key = "apteka_review"
+const TextAptekaApproved i18n.Key = "apteka_approved"
+const TextAptekaRejected i18n.Key = "apteka_rejected"
+const TextAptekaRefused i18n.Key = "apteka_refused"
+const TextAptekaReviewed i18n.Key = "apteka_reviewed"
+const TextNoAccess i18n.Key = "no_access"
+const TextNoAptekas i18n.Key = "no_aptekas"
+const TextInviteWho i18n.Key = "invite_who"
+const TextInviteCreated i18n.Key = "invite_created"
+const TextInviteInvalid i18n.Key = "invite_invalid"
+const TextJoinedApteka i18n.Key = "joined_apteka"
+const TextJoinedChain i18n.Key = "joined_chain"
+const TextCreateChainUsage i18n.Key = "create_chain_usage"
+
+const TextAskLocation i18n.Key = "ask_location"
+const TextLocationSaved i18n.Key = "location_saved"
+
+const TextWebAppError i18n.Key = "webapp_error"
+
+const TextPhotoUnsupported i18n.Key = "photo_unsupported"
+const TextPhotoTooBig i18n.Key = "photo_too_big"
+const TextTooManyPhotos i18n.Key = "too_many_photos"
+const TextPhotoAsFile i18n.Key = "photo_as_file"
+
+const TextHousePreview i18n.Key = "house_preview"
+const TextHouseEdit i18n.Key = "house_edit"
+const TextHouseCancelled i18n.Key = "house_cancelled"
+const TextHouseNotFound i18n.Key = "house_not_found"
+const TextHouseUnavailable i18n.Key = "house_unavailable"
+const TextHousesNotFound i18n.Key = "houses_not_found"
+const TextHouseSearchExpired i18n.Key = "house_search_expired"
+const TextHousesPage i18n.Key = "houses_page"
+
+const TextNoListings i18n.Key = "no_listings"
+const TextListingEdit i18n.Key = "listing_edit"
+const TextListingUpdated i18n.Key = "listing_updated"
+const TextListingSendPhotos i18n.Key = "listing_send_photos"
+const TextListingNoPhotos i18n.Key = "listing_no_photos"
+const TextListingPhotosReplaced i18n.Key = "listing_photos_replaced"
+
+const TextVerifyPhone i18n.Key = "verify_phone"
+const TextPhoneVerified i18n.Key = "phone_verified"
+const TextContactNotYours i18n.Key = "contact_not_yours"
+const TextPhoneUnsupported i18n.Key = "phone_unsupported"
+const TextPhoneMismatch i18n.Key = "phone_mismatch"
+
+const TextHouseSubmitted i18n.Key = "house_submitted"
+const TextHouseApproved i18n.Key = "house_approved"
+const TextHouseRejected i18n.Key = "house_rejected"
+const TextModerationNew i18n.Key = "moderation_new"
+const TextModerationApproved i18n.Key = "moderation_approved"
+const TextModerationRejected i18n.Key = "moderation_rejected"
+const TextModerationDone i18n.Key = "moderation_done"
+const TextModerationAskReason i18n.Key = "moderation_ask_reason"
+const TextModerationReasonTooLong i18n.Key = "moderation_reason_too_long"
+
+// RejectReasons are offered to moderators when rejecting a listing, the key
+// is saved as the reason and shown to the owner in their language
+var RejectReasons = []i18n.Key{
+	"reject_phone",
+	"reject_duplicate",
+	"reject_content",
+	"reject_photos",
+	"reject_address",
+}
+
+// TextRejectReason translates a reason chosen among RejectReasons, a reason
+// written by the moderator is shown as is
+func TextRejectReason(lang i18n.Lang, reason string) string {
+	if slices.Contains(RejectReasons, i18n.Key(reason)) {
+		return i18n.T(lang, i18n.Key(reason))
+	}
+	return reason
+}
+
+const TextAlertSaved i18n.Key = "alert_saved"
+const TextAlertDeleted i18n.Key = "alert_deleted"
+const TextTooManyAlerts i18n.Key = "too_many_alerts"
+const TextNoAlerts i18n.Key = "no_alerts"
+const TextAlerts i18n.Key = "alerts"
+const TextAllHouses i18n.Key = "all_houses"
+const TextNewHouse i18n.Key = "new_house"
+
+const TextLanguageChoose i18n.Key = "language_choose"
+const TextLanguageChanged i18n.Key = "language_changed"
+
+const TextSessionNotAllowed i18n.Key = "session_not_allowed"
+const TextSessionTopic i18n.Key = "session_topic"
+const TextSessionChooseTopic i18n.Key = "session_choose_topic"
+const TextSessionRate i18n.Key = "session_rate"
+const TextSessionTopicFirst i18n.Key = "session_topic_first"
+const TextSessionTopicNeeded i18n.Key = "session_topic_needed"
+const TextSessionLastClosed i18n.Key = "session_last_closed"
+const TextSessionClosed i18n.Key = "session_closed"
+const TextSessionLimit i18n.Key = "session_limit"
+const TextSessionNone i18n.Key = "session_none"
+const TextSessionFinished i18n.Key = "session_finished"
+const TextSessionTrial i18n.Key = "session_trial"
+const TextSessionExpired i18n.Key = "session_expired"
+const TextSessionUnlimited i18n.Key = "session_unlimited"
+const TextSessionNoLeft i18n.Key = "session_no_left"
+const TextSessionAllowed i18n.Key = "session_allowed"
+const TextServerError i18n.Key = "server_error"
+const TextSupportPrompt i18n.Key = "support_prompt"
+const TextSupportSent i18n.Key = "support_sent"
+const TextFeedbackPrompt i18n.Key = "feedback_prompt"
+const TextFeedbackThanks i18n.Key = "feedback_thanks"
+
+const TextUserNotFound i18n.Key = "user_not_found"
+const TextPlanPremiumActive i18n.Key = "plan_premium_active"
+const TextPlanStandardActive i18n.Key = "plan_standard_active"
+const TextPlanBasicActive i18n.Key = "plan_basic_active"
+const TextPlanTrialExpired i18n.Key = "plan_trial_expired"
+const TextPlanTrialActive i18n.Key = "plan_trial_active"
+const TextPlanNone i18n.Key = "plan_none"
+const TextPlanBuy i18n.Key = "plan_buy"
+const TextPlanBasic i18n.Key = "plan_basic"
+const TextPlanStandard i18n.Key = "plan_standard"
+const TextPlanPremium i18n.Key = "plan_premium"
+const TextSubscriptionInvalid i18n.Key = "subscription_invalid"
+const TextSubscriptionError i18n.Key = "subscription_error"
+const TextSubscriptionDone i18n.Key = "subscription_done"
+
+const ButtonVerifyPhone i18n.Key = "btn_verify_phone"
+const ButtonSendLocation i18n.Key = "btn_send_location"
+const ButtonCreateApteka i18n.Key = "btn_create_apteka"
+const ButtonInviteManager i18n.Key = "btn_invite_manager"
+const ButtonShow i18n.Key = "btn_show"
+const ButtonOpen2GIS i18n.Key = "btn_open_2gis"
+const ButtonPublish i18n.Key = "btn_publish"
+const ButtonEdit i18n.Key = "btn_edit"
+const ButtonCancel i18n.Key = "btn_cancel"
+const ButtonPhotos i18n.Key = "btn_photos"
+const ButtonNotifyNew i18n.Key = "btn_notify_new"
+const ButtonUnsubscribe i18n.Key = "btn_unsubscribe"
+const ButtonUnpublish i18n.Key = "btn_unpublish"
+const ButtonSubmit i18n.Key = "btn_submit"
+const ButtonReplacePhotos i18n.Key = "btn_replace_photos"
+const ButtonDelete i18n.Key = "btn_delete"
+const ButtonDeleteConfirm i18n.Key = "btn_delete_confirm"
+const ButtonNo i18n.Key = "btn_no"
+const ButtonDone i18n.Key = "btn_done"
+const ButtonEditListing i18n.Key = "btn_edit_listing"
+const ButtonApprove i18n.Key = "btn_approve"
+const ButtonReject i18n.Key = "btn_reject"
+const ButtonBack i18n.Key = "btn_back"
+const ButtonRejectOther i18n.Key = "btn_reject_other"
 
 const EmojiPill = "💊"
 const EmojiHospital = "🏥"
 const EmojiPin = "📍"
 const EmojiPhone = "📞"
+const EmojiClock = "🕘"
+const EmojiBox = "📦"
+const EmojiHouse = "🏠"
+const EmojiDoor = "🚪"
+const EmojiCamera = "📷"
+const EmojiMoney = "💰"
+const EmojiUser = "👤"
+
+func TextListingStatus(lang i18n.Lang, house *model.House) string {
+	switch {
+	case house.Status == model.HousePending:
+		return i18n.T(lang, "status_pending")
+	case house.Status == model.HouseRejected:
+		return i18n.T(lang, "status_rejected", TextRejectReason(lang, house.RejectReason))
+	case house.Status == model.HouseApproved && house.Active:
+		return i18n.T(lang, "status_published")
+	case house.Status == model.HouseApproved:
+		return i18n.T(lang, "status_unpublished")
+	default:
+		return i18n.T(lang, "status_draft")
+	}
+}
+
+// TextFlag explains an automatic check flag, flags are keys of the catalogs
+func TextFlag(lang i18n.Lang, flag string) string {
+	return i18n.T(lang, i18n.Key("flag_"+flag))
+}
+
+// boundsText renders a range where zero means no bound
+func boundsText(lang i18n.Lang, from, to int) string {
+	switch {
+	case from > 0 && to > 0 && from == to:
+		return strconv.Itoa(from)
+	case from > 0 && to > 0:
+		return strconv.Itoa(from) + "-" + strconv.Itoa(to)
+	case from > 0:
+		return i18n.T(lang, "bounds_from", from)
+	default:
+		return i18n.T(lang, "bounds_to", to)
+	}
+}
+
+func TextPrice(lang i18n.Lang, price int) string {
+	if price == 0 {
+		return i18n.T(lang, "price_negotiable")
+	}
+	digits := strconv.Itoa(price)
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(d)
+	}
+	return i18n.T(lang, "price", sb.String())
+}
+
+func TextRooms(lang i18n.Lang, n int) string {
+	return i18n.N(lang, "rooms", n, n)
+}
+
+func TextPhotos(lang i18n.Lang, n int) string {
+	return i18n.N(lang, "photos", n, n)
+}
+
+// TextStockLineError names the skipped line of the stock file and the reason
+func TextStockLineError(lang i18n.Lang, e stock.LineError) string {
+	where := e.Name
+	if e.Line > 0 {
+		where = i18n.T(lang, "stock_line", e.Line)
+	}
+	return where + ": " + i18n.T(lang, i18n.Key("stock_error_"+e.Code), e.Value)
+}
+
+func TextValidationErrors(lang i18n.Lang, fields []model.FieldError) string {
+	text := i18n.T(lang, "validation_errors")
+	for _, f := range fields {
+		text += "\n• " + TextFieldError(lang, f)
+	}
+	return text
+}
+
+// TextFieldError names the field and explains the code, a field may have
+// its own explanation of a code, like the expected phone format
+func TextFieldError(lang i18n.Lang, f model.FieldError) string {
+	name := f.Field
+	if label := i18n.Key("label_" + f.Field); i18n.Has(label) {
+		name = i18n.T(lang, label)
+	}
+	key := i18n.Key("error_" + f.Field + "_" + f.Code)
+	if !i18n.Has(key) {
+		key = i18n.Key("error_" + f.Code)
+	}
+	args := make([]any, 0, len(f.Params))
+	for _, p := range f.Params {
+		args = append(args, p)
+	}
+	return name + ": " + i18n.T(lang, key, args...)
+}
+
+var weekdayShort = [7]i18n.Key{
+	"weekday_sun", "weekday_mon", "weekday_tue", "weekday_wed", "weekday_thu", "weekday_fri", "weekday_sat",
+}
+
+// workStatus renders "Открыто до 21:00" or "Закрыто, откроется Пн в 9:00"
+func workStatus(lang i18n.Lang, w model.WorkHours, now time.Time) string {
+	if w.IsZero() {
+		return ""
+	}
+	if w.Is24x7() {
+		return i18n.T(lang, "open_24x7")
+	}
+	if w.IsOpenAt(now) {
+		if closes, ok := w.ClosesAt(now); ok {
+			return i18n.T(lang, "open_until", closes.Format("15:04"))
+		}
+		return i18n.T(lang, "open")
+	}
+
+	opens, ok := w.NextOpening(now)
+	if !ok {
+		return i18n.T(lang, "closed")
+	}
+	now = now.In(opens.Location())
+	if opens.YearDay() == now.YearDay() && opens.Year() == now.Year() {
+		return i18n.T(lang, "closed_opens_at", opens.Format("15:04"))
+	}
+	return i18n.T(lang, "closed_opens_on", i18n.T(lang, weekdayShort[opens.Weekday()]), opens.Format("15:04"))
+}
+
+// inventoryUpdated renders "остатки обновлены 5 дней назад"
+func inventoryUpdated(lang i18n.Lang, apteka *model.Apteka, now time.Time) string {
+	if apteka.LastInventoryUpdate.IsZero() {
+		return i18n.T(lang, "inventory_never")
+	}
+	days := int(now.Sub(apteka.LastInventoryUpdate).Hours() / 24)
+	if days == 0 {
+		return i18n.T(lang, "inventory_today")
+	}
+	return i18n.N(lang, "inventory_days", days, days)
+}
+
+func TextInventoryReminder(lang i18n.Lang, apteka *model.Apteka, now time.Time) string {
+	return i18n.T(lang, "inventory_reminder", apteka.Name, apteka.Address, inventoryUpdated(lang, apteka, now))
+}
+
+func TextInventoryEscalation(lang i18n.Lang, apteka *model.Apteka, now time.Time) string {
+	return i18n.T(lang, "inventory_escalation", apteka.Name, apteka.Address,
+		inventoryUpdated(lang, apteka, now), apteka.MissedReminders)
+}
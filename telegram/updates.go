@@ -0,0 +1,118 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// how the bot receives updates
+const (
+	UpdatesPolling = "polling"
+	UpdatesWebhook = "webhook"
+)
+
+// WebhookPath is served by the HTTP server in the webhook mode
+const WebhookPath = webhookPrefix + webhookName
+
+const (
+	webhookPrefix = "/telegram/"
+	webhookName   = "webhook"
+)
+
+// Telegram accepts only these characters in the secret token
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// UpdatesConfig chooses between long polling and webhook
+type UpdatesConfig struct {
+	// Mode is "polling" or "webhook", polling if empty
+	Mode string
+	// WebhookURL is the public https url of the HTTP server, WebhookPath is appended to it
+	WebhookURL string
+	// WebhookSecret is sent back by Telegram in the X-Telegram-Bot-Api-Secret-Token header
+	WebhookSecret string
+	// DropPendingUpdates skips the updates sent while the bot was stopped
+	DropPendingUpdates bool
+}
+
+// Validate tells what is missing for the chosen mode
+func (c UpdatesConfig) Validate() error {
+	switch c.Mode {
+	case UpdatesPolling, "":
+		return nil
+	case UpdatesWebhook:
+	default:
+		return fmt.Errorf("unknown updates mode %q", c.Mode)
+	}
+
+	u, err := url.Parse(c.WebhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("webhook url %q is not an https url", c.WebhookURL)
+	}
+	if !webhookSecretPattern.MatchString(c.WebhookSecret) {
+		return errors.New("webhook secret must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+	}
+	return nil
+}
+
+func (c UpdatesConfig) webhook() bool {
+	return c.Mode == UpdatesWebhook
+}
+
+// startUpdates sets the webhook or starts polling, the updates sent while
+// the bot was stopped are processed unless DropPendingUpdates is set
+func (lp *LongPoll) startUpdates() error {
+	if !lp.updates.webhook() {
+		// the webhook left by the webhook mode would make getUpdates fail
+		return lp.updater.StartPolling(lp.bot, &ext.PollingOpts{
+			DropPendingUpdates:    lp.updates.DropPendingUpdates,
+			EnableWebhookDeletion: true,
+			GetUpdatesOpts: &gotgbot.GetUpdatesOpts{
+				Timeout: 9,
+				RequestOpts: &gotgbot.RequestOpts{
+					Timeout: time.Second * 10,
+				},
+			},
+		})
+	}
+
+	err := lp.updater.AddWebhook(lp.bot, webhookName, &ext.AddWebhookOpts{SecretToken: lp.updates.WebhookSecret})
+	if err != nil {
+		return err
+	}
+	webhookURL := strings.TrimSuffix(lp.updates.WebhookURL, "/") + WebhookPath
+	_, err = lp.bot.SetWebhook(webhookURL, &gotgbot.SetWebhookOpts{
+		SecretToken:        lp.updates.WebhookSecret,
+		DropPendingUpdates: lp.updates.DropPendingUpdates,
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Webhook is set to %s", webhookURL)
+	return nil
+}
+
+// WebhookHandler receives the updates in the webhook mode, requests
+// without the secret token are rejected
+func (lp *LongPoll) WebhookHandler() http.HandlerFunc {
+	return lp.updater.GetHandlerFunc(webhookPrefix)
+}
+
+// Stop stops receiving updates, the webhook is deleted without dropping
+// the pending updates so that they are delivered after the restart
+func (lp *LongPoll) Stop() error {
+	if lp.updates.webhook() {
+		if _, err := lp.bot.DeleteWebhook(nil); err != nil {
+			log.Printf("Could not delete webhook: %s", err.Error())
+		}
+	}
+	return lp.updater.Stop()
+}
@@ -0,0 +1,29 @@
+package voice
+
+import "strings"
+
+// whisper reports the detected language by its english name
+var languageCodes = map[string]string{
+	"russian": "ru",
+	"kazakh":  "kk",
+	"uzbek":   "uz",
+	"english": "en",
+}
+
+func languageCode(name string) string {
+	name = strings.ToLower(name)
+	if code, ok := languageCodes[name]; ok {
+		return code
+	}
+	return name
+}
+
+// Supported tells whether speech in the language is expected from users
+func Supported(code string) bool {
+	for _, supported := range languageCodes {
+		if code == supported {
+			return true
+		}
+	}
+	return false
+}
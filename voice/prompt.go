@@ -0,0 +1,70 @@
+package voice
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Whisper considers only the last 224 tokens of the prompt
+const maxPromptTokens = 224
+
+// tokens of the previous chunk text that keep the context across the cut
+const contextTokens = 64
+
+// runeCost is the estimated cost of the rune in thirds of a token, latin
+// words take about a token per three letters while the multilingual
+// tokenizer spends up to a token on a cyrillic letter or a punctuation mark
+func runeCost(r rune) int {
+	if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return 1
+	}
+	return 3
+}
+
+// estimateTokens is an upper estimate of the Whisper tokens in the text
+func estimateTokens(s string) int {
+	cost := 0
+	for _, r := range s {
+		cost += runeCost(r)
+	}
+	return (cost + 2) / 3
+}
+
+// Vocabulary joins the names into a Whisper prompt of at most budget
+// tokens, the first names fitting the budget are taken
+func Vocabulary(names []string, budget int) string {
+	var sb strings.Builder
+	for _, name := range names {
+		next := name
+		if sb.Len() > 0 {
+			next = ", " + name
+		}
+		if estimateTokens(sb.String()+next) > budget {
+			break
+		}
+		sb.WriteString(next)
+	}
+	return sb.String()
+}
+
+// tailTokens returns the end of the text that fits in n tokens
+func tailTokens(text string, n int) string {
+	runes := []rune(text)
+	cost, start := 0, len(runes)
+	for start > 0 && cost+runeCost(runes[start-1]) <= 3*n {
+		start--
+		cost += runeCost(runes[start])
+	}
+	return string(runes[start:])
+}
+
+// chunkPrompt is the vocabulary followed by the end of the previous chunk
+// text, the context goes last because Whisper drops the beginning of a
+// longer prompt
+func chunkPrompt(vocabulary []string, previous string) string {
+	if previous == "" {
+		return Vocabulary(vocabulary, maxPromptTokens)
+	}
+	context := tailTokens(previous, contextTokens)
+	return strings.TrimSpace(Vocabulary(vocabulary, maxPromptTokens-estimateTokens(context)-1) + " " + context)
+}
@@ -0,0 +1,93 @@
+package voice
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"Nurofen", 3},
+		{"Нурофен", 7},
+		{"Но-шпа, 40", 9},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.s); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestVocabulary(t *testing.T) {
+	names := []string{"Нурофен", "Парацетамол", "Аспирин", "Но-шпа"}
+	tests := []struct {
+		budget int
+		want   string
+	}{
+		{100, "Нурофен, Парацетамол, Аспирин, Но-шпа"},
+		{20, "Нурофен, Парацетамол"},
+		{19, "Нурофен"},
+		{5, ""},
+	}
+	for _, tt := range tests {
+		if got := Vocabulary(names, tt.budget); got != tt.want {
+			t.Errorf("Vocabulary(%d) = %q, want %q", tt.budget, got, tt.want)
+		}
+	}
+
+	many := make([]string, 500)
+	for i := range many {
+		many[i] = "Ацетилсалициловая кислота"
+	}
+	if got := estimateTokens(Vocabulary(many, maxPromptTokens)); got > maxPromptTokens || got < maxPromptTokens-30 {
+		t.Errorf("vocabulary of many names takes %d tokens, want close to %d", got, maxPromptTokens)
+	}
+}
+
+func TestTailTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"купить нурофен", 100, "купить нурофен"},
+		{"купить нурофен", 7, "нурофен"},
+		{"buy nurofen", 3, "nurofen"},
+		{"нурофен", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := tailTokens(tt.text, tt.n); got != tt.want {
+			t.Errorf("tailTokens(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestChunkPrompt(t *testing.T) {
+	vocabulary := make([]string, 100)
+	for i := range vocabulary {
+		vocabulary[i] = "Парацетамол"
+	}
+	previous := strings.Repeat("и еще мне нужен нурофен ", 50)
+
+	first := chunkPrompt(vocabulary, "")
+	if n := estimateTokens(first); n > maxPromptTokens {
+		t.Errorf("first chunk prompt takes %d tokens", n)
+	}
+
+	next := chunkPrompt(vocabulary, previous)
+	if n := estimateTokens(next); n > maxPromptTokens {
+		t.Errorf("next chunk prompt takes %d tokens", n)
+	}
+	if !strings.HasPrefix(next, "Парацетамол") || !strings.HasSuffix(next, "нурофен") {
+		t.Errorf("next chunk prompt %q must start with the vocabulary and end with the previous text", next)
+	}
+
+	if got := chunkPrompt(nil, "нурофен"); got != "нурофен" {
+		t.Errorf("chunkPrompt without vocabulary = %q", got)
+	}
+}
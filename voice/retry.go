@@ -0,0 +1,58 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const maxAttempts = 4
+
+// the wait after the first failure, it doubles after each next one
+var retryBackoff = time.Second
+
+// retry calls fn until it succeeds or fails with a permanent error,
+// waiting twice longer after each transient failure
+func retry(ctx context.Context, fn func() error) error {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == maxAttempts || !isTransient(err) {
+			return err
+		}
+		log.Printf("OpenAI attempt %d failed, retrying in %s: %s", attempt, backoff, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransient allows retrying only timeouts, rate limits and server errors,
+// anything else would fail again the same way
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == 429 || apiErr.HTTPStatusCode >= 500
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == 429 || reqErr.HTTPStatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
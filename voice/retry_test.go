@@ -0,0 +1,95 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limit", &openai.APIError{HTTPStatusCode: 429}, true},
+		{"server error", &openai.APIError{HTTPStatusCode: 503}, true},
+		{"bad request", &openai.APIError{HTTPStatusCode: 400}, false},
+		{"unauthorized", &openai.APIError{HTTPStatusCode: 401}, false},
+		{"request server error", &openai.RequestError{HTTPStatusCode: 502}, true},
+		{"request not found", &openai.RequestError{HTTPStatusCode: 404}, false},
+		{"wrapped", fmt.Errorf("chunk 2: %w", &openai.APIError{HTTPStatusCode: 500}), true},
+		{"timeout", &url.Error{Op: "Post", URL: "https://api.openai.com", Err: timeoutError{}}, true},
+		{"connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, false},
+		{"canceled", context.Canceled, false},
+		{"deadline", fmt.Errorf("post: %w", context.DeadlineExceeded), false},
+		{"file", os.ErrNotExist, false},
+		{"eof", io.ErrUnexpectedEOF, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("%s: isTransient(%v) = %t, want %t", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = time.Second }()
+
+	tests := []struct {
+		name     string
+		errs     []error
+		attempts int
+		fails    bool
+	}{
+		{"success", nil, 1, false},
+		{"transient then success", []error{&openai.APIError{HTTPStatusCode: 429}}, 2, false},
+		{"permanent", []error{&openai.APIError{HTTPStatusCode: 400}}, 1, true},
+		{"always transient", []error{
+			&openai.APIError{HTTPStatusCode: 500}, &openai.APIError{HTTPStatusCode: 500},
+			&openai.APIError{HTTPStatusCode: 500}, &openai.APIError{HTTPStatusCode: 500},
+			&openai.APIError{HTTPStatusCode: 500},
+		}, maxAttempts, true},
+	}
+	for _, tt := range tests {
+		attempts := 0
+		err := retry(context.Background(), func() error {
+			attempts++
+			if attempts <= len(tt.errs) {
+				return tt.errs[attempts-1]
+			}
+			return nil
+		})
+		if attempts != tt.attempts || (err != nil) != tt.fails {
+			t.Errorf("%s: %d attempts, error %v", tt.name, attempts, err)
+		}
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	err := retry(ctx, func() error {
+		attempts++
+		cancel()
+		return &openai.APIError{HTTPStatusCode: 503}
+	})
+	if attempts != 1 || !errors.Is(err, context.Canceled) {
+		t.Errorf("%d attempts, error %v, want to stop on cancel", attempts, err)
+	}
+}
@@ -0,0 +1,45 @@
+package voice
+
+import (
+	"context"
+	"io"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// the speech API does not accept longer input
+const maxSpeechInput = 4096
+
+// Speaker synthesizes speech with OpenAI text to speech
+type Speaker struct {
+	openaiClient *openai.Client
+}
+
+func NewSpeaker(openaiClient *openai.Client) *Speaker {
+	return &Speaker{openaiClient: openaiClient}
+}
+
+// Speak returns the text read aloud as ogg opus, ready to be sent as a voice message
+func (s *Speaker) Speak(ctx context.Context, text string) ([]byte, error) {
+	if runes := []rune(text); len(runes) > maxSpeechInput {
+		text = string(runes[:maxSpeechInput])
+	}
+
+	var audio []byte
+	err := retry(ctx, func() error {
+		resp, err := s.openaiClient.CreateSpeech(ctx, openai.CreateSpeechRequest{
+			Model:          openai.TTSModel1,
+			Input:          text,
+			Voice:          openai.VoiceAlloy,
+			ResponseFormat: openai.SpeechResponseFormatOpus,
+		})
+		if err != nil {
+			return err
+		}
+		defer resp.Close()
+
+		audio, err = io.ReadAll(resp)
+		return err
+	})
+	return audio, err
+}
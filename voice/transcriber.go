@@ -0,0 +1,157 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ChunkDuration is the length of the parts long recordings are split into
+const ChunkDuration = 2 * time.Minute
+
+// DefaultLanguage is used when the detected language is not supported
+const DefaultLanguage = "ru"
+
+// whisper does not accept bigger files
+const whisperMaxSize = 25 * 1024 * 1024
+
+var ErrTooBig = errors.New("audio is too big to be transcribed without ffmpeg")
+
+// Transcriber recognizes speech with Whisper, recordings are transcoded to
+// small mono opus and split into chunks with ffmpeg when it is installed
+type Transcriber struct {
+	openaiClient *openai.Client
+	ffmpeg       string
+}
+
+type Result struct {
+	Text string
+	// Language is the ISO 639-1 code of the speech
+	Language string
+}
+
+func NewTranscriber(openaiClient *openai.Client) *Transcriber {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		log.Printf("ffmpeg is not found, audio is sent to Whisper as is")
+	}
+	return &Transcriber{openaiClient: openaiClient, ffmpeg: ffmpeg}
+}
+
+// Transcribe recognizes the recording, the language is detected if empty,
+// the vocabulary lists words expected in the speech, most important first
+func (t *Transcriber) Transcribe(ctx context.Context, fileName string, r io.Reader, vocabulary []string, language string) (*Result, error) {
+	dir, err := os.MkdirTemp("", "voice")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input"+filepath.Ext(fileName))
+	size, err := writeFile(input, r)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := []string{input}
+	if t.ffmpeg != "" {
+		if chunks, err = t.split(ctx, input, dir); err != nil {
+			return nil, err
+		}
+	} else if size > whisperMaxSize {
+		return nil, ErrTooBig
+	}
+
+	result := &Result{Language: language}
+	texts := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		previous := ""
+		if i > 0 {
+			previous = texts[i-1]
+		}
+		prompt := chunkPrompt(vocabulary, previous)
+
+		resp, err := t.transcribeChunk(ctx, chunk, prompt, result.Language)
+		if err != nil {
+			return nil, err
+		}
+		if result.Language == "" {
+			result.Language = languageCode(resp.Language)
+			// short russian phrases are sometimes taken for another language
+			if !Supported(result.Language) {
+				result.Language = DefaultLanguage
+				if resp, err = t.transcribeChunk(ctx, chunk, prompt, DefaultLanguage); err != nil {
+					return nil, err
+				}
+			}
+		}
+		texts = append(texts, strings.TrimSpace(resp.Text))
+	}
+
+	result.Text = strings.Join(texts, " ")
+	return result, nil
+}
+
+// split transcodes the recording to 16 kHz mono opus and cuts it into chunks
+func (t *Transcriber) split(ctx context.Context, input, dir string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-i", input,
+		"-vn", "-ac", "1", "-ar", "16000", "-c:a", "libopus", "-b:a", "24k",
+		"-f", "segment", "-segment_time", fmt.Sprint(ChunkDuration.Seconds()),
+		filepath.Join(dir, "chunk%03d.ogg"),
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	chunks, err := filepath.Glob(filepath.Join(dir, "chunk*.ogg"))
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, errors.New("ffmpeg produced no audio")
+	}
+	sort.Strings(chunks)
+	return chunks, nil
+}
+
+// transcribeChunk calls Whisper retrying transient failures
+func (t *Transcriber) transcribeChunk(ctx context.Context, path, prompt, language string) (resp openai.AudioResponse, err error) {
+	err = retry(ctx, func() error {
+		resp, err = t.openaiClient.CreateTranscription(ctx, openai.AudioRequest{
+			Model:    openai.Whisper1,
+			FilePath: path,
+			Prompt:   prompt,
+			Language: language,
+			Format:   openai.AudioResponseFormatVerboseJSON,
+		})
+		return err
+	})
+	return resp, err
+}
+
+func writeFile(path string, r io.Reader) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return size, err
+}